@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/asegev/vsphere-cli/internal/cli"
@@ -8,6 +9,11 @@ import (
 
 func main() {
 	if err := cli.Execute(); err != nil {
+		// Commands such as guest exec report the exit code of a remote process
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
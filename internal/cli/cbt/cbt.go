@@ -0,0 +1,27 @@
+package cbt
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewCBTCmd creates the cbt command
+func NewCBTCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cbt",
+		Short: "Manage Changed Block Tracking",
+		Long: `Inspect and manage Changed Block Tracking (CBT) on virtual machines.
+
+Available subcommands:
+  status   - Show the CBT state of a VM and its disks
+  enable   - Enable CBT on a VM and all its disks
+  disable  - Disable CBT on a VM and all its disks
+  query    - Print the disk areas changed since a change ID`,
+	}
+
+	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newEnableCmd())
+	cmd.AddCommand(newDisableCmd())
+	cmd.AddCommand(newQueryCmd())
+
+	return cmd
+}
@@ -0,0 +1,131 @@
+package cbt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	enableActivate  bool
+	disableActivate bool
+)
+
+func newEnableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable <vm>",
+		Short: "Enable CBT on a VM and all its disks",
+		Long: `Sets ctkEnabled on the VM and <node>.ctkEnabled on every disk.
+
+CBT becomes active after the next stun/unstun cycle. With --activate, a
+throwaway snapshot is created and removed right away to activate it on a
+running VM. Powered-off VMs activate CBT on their next power on.
+
+Examples:
+  vcli cbt enable my-vm
+  vcli cbt enable my-vm --activate`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setCBT(cmd, args[0], true, enableActivate)
+		},
+	}
+
+	cmd.Flags().BoolVar(&enableActivate, "activate", false, "Take and remove a throwaway snapshot to activate the change")
+
+	return cmd
+}
+
+func newDisableCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable <vm>",
+		Short: "Disable CBT on a VM and all its disks",
+		Long: `Clears ctkEnabled on the VM and <node>.ctkEnabled on every disk.
+
+With --activate, a throwaway snapshot is created and removed right away so a
+running VM stops tracking changes immediately.
+
+Examples:
+  vcli cbt disable my-vm
+  vcli cbt disable my-vm --activate`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setCBT(cmd, args[0], false, disableActivate)
+		},
+	}
+
+	cmd.Flags().BoolVar(&disableActivate, "activate", false, "Take and remove a throwaway snapshot to activate the change")
+
+	return cmd
+}
+
+// setCBT reconfigures CBT on a VM and optionally cycles a snapshot to apply it
+func setCBT(cmd *cobra.Command, vmName string, enabled, activate bool) error {
+	ctx := cmd.Context()
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+
+	c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+	if err != nil {
+		return err
+	}
+
+	dcm := vmware.NewVMManager(c)
+
+	vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
+	if err != nil {
+		return err
+	}
+
+	if err := vsphere.SetCBT(ctx, vm, enabled); err != nil {
+		return fmt.Errorf("failed to reconfigure CBT: %w", err)
+	}
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	fmt.Printf("CBT %s on %s\n", state, vmName)
+
+	if !activate {
+		return nil
+	}
+
+	powerState, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if powerState != types.VirtualMachinePowerStatePoweredOn {
+		fmt.Printf("%s is not powered on; the change applies on next power on\n", vmName)
+		return nil
+	}
+
+	snapshotName := fmt.Sprintf("vcli-cbt-activate-%s", time.Now().UTC().Format("20060102-150405"))
+	if err := dcm.CreateSnapshot(ctx, vmware.CreateSnapshotRequest{
+		VmMoid:       vm.Reference().Value,
+		SnapshotName: snapshotName,
+		Description:  "Temporary snapshot created by vcli cbt to activate CBT",
+	}); err != nil {
+		return fmt.Errorf("failed to create activation snapshot: %w", err)
+	}
+
+	if err := dcm.RemoveSnapshot(ctx, vmware.RemoveSnapshotRequest{
+		VmMoid:       vm.Reference().Value,
+		SnapshotName: snapshotName,
+		Consolidate:  true,
+	}); err != nil {
+		return fmt.Errorf("failed to remove activation snapshot %s (remove it manually): %w", snapshotName, err)
+	}
+
+	fmt.Printf("CBT change activated on %s\n", vmName)
+	return nil
+}
@@ -0,0 +1,64 @@
+package cbt
+
+import (
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <vm>",
+		Short: "Show the CBT state of a VM and its disks",
+		Long: `Shows whether Changed Block Tracking is enabled on a VM (ctkEnabled) and on
+each disk (<node>.ctkEnabled), and whether each disk reports a change ID.
+
+A disk is only active once CBT has gone through a stun/unstun cycle, such as
+a power on or a snapshot create/remove (see vcli cbt enable --activate).
+
+Examples:
+  vcli cbt status my-vm
+  vcli cbt status my-vm -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			status, err := vsphere.GetCBTStatus(ctx, vm)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"DISK", "NODE", "VM CTK", "DISK CTK", "ACTIVE", "CHANGE ID"}
+			return formatter.Print(status, headers, func(data interface{}) [][]string {
+				s := data.(*vsphere.CBTStatus)
+				rows := [][]string{}
+				for _, d := range s.Disks {
+					rows = append(rows, []string{d.Label, d.Node, output.FormatBool(s.Enabled), output.FormatBool(d.Enabled), output.FormatBool(d.Active), output.OrNone(d.ChangeID)})
+				}
+				return rows
+			})
+		},
+	}
+
+	return cmd
+}
@@ -12,12 +12,14 @@ func NewCloneCmd() *cobra.Command {
 		Long: `Clone virtual machines with basic cloning capabilities.
 
 Available subcommands:
-  create  - Create a full clone of a VM
-  list    - List cloned VMs`,
+  create  - Create linked, instant or template clones of a VM
+  delete  - Delete clones created by vcli
+  gc      - Delete clones whose TTL has expired`,
 	}
 
 	cmd.AddCommand(newCreateCmd())
 	cmd.AddCommand(newDeleteCmd())
+	cmd.AddCommand(newGCCmd())
 
 	return cmd
 }
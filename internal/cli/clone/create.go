@@ -1,10 +1,21 @@
 package clone
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"os"
+	"text/template"
+	"time"
+
 	"github.com/asegev/vsphere-cli/internal/global"
 	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/asegev/vsphere-cli/pkg/workerpool"
 	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/spf13/cobra"
 )
@@ -13,62 +24,396 @@ var (
 	vmName       string
 	snapshotName string
 	cloneName    string
+
+	createCount        int
+	createNameTemplate string
+	createParallel     int
+	createRetries      int
+	createPowerOn      bool
+	createWaitIP       bool
+	createIPTimeout    time.Duration
+	createTTL          time.Duration
+	createFromTemplate bool
+	createResourcePool string
+	createMode         string
+	createRefreshMAC   bool
+	createGuestInfo    map[string]string
 )
 
+// Clone modes supported by clone create
+const (
+	modeLinked  = "linked"
+	modeInstant = "instant"
+)
+
+// cloneResult is the per-clone outcome reported by clone create
+type cloneResult struct {
+	Name      string `json:"name" yaml:"name"`
+	Moid      string `json:"moid,omitempty" yaml:"moid,omitempty"`
+	Status    string `json:"status" yaml:"status"`
+	PoweredOn bool   `json:"poweredOn" yaml:"poweredOn"`
+	IPAddress string `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
+	Attempts  int    `json:"attempts" yaml:"attempts"`
+	Duration  string `json:"duration" yaml:"duration"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// nameTemplateData is the data available to --name-template and --guestinfo values
+type nameTemplateData struct {
+	Index    int
+	Source   string
+	Snapshot string
+}
+
 func newCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "create <source-vm> <new-name>",
-		Short: "Create a full linked of a VM",
-		Args:  cobra.NoArgs,
+		Use:   "create [source-vm] [new-name]",
+		Short: "Create one or more clones of a VM",
+		Long: `Creates linked clones of a VM from one of its snapshots.
+
+With --from-template the source is a template instead, and full clones are
+placed in the root resource pool of the default cluster or --resource-pool.
+
+With --mode instant the clones are vSphere Instant Clones of the running
+source VM (vCenter 6.7 or later). They start powered on and share the
+source's memory state, so by default every NIC gets a new MAC address; use
+--guestinfo to pass guestinfo.* variables a guest script can use to refresh
+its hostname and network configuration. --guestinfo values are templates like
+--name-template, rendered for each clone.
+
+A single clone is created by default. Use --count with --name-template to
+create a batch of identical clones concurrently. The template is a Go
+template with the fields .Index (starting at 1), .Source and .Snapshot.
+
+Each clone is tagged with vcli.* custom attributes recording its source VM
+and snapshot, which clone delete uses to tell clones apart from other VMs.
+With --ttl the clone is also stamped with an expiry time, after which
+clone gc deletes it.
+
+Clones that fail with a transient vSphere error (task in progress, concurrent
+access, host communication) are retried up to --retries times.
+
+Examples:
+  vcli clone create my-vm my-clone
+  vcli clone create my-vm --count 10 --name-template 'ci-{{.Index}}'
+  vcli clone create my-vm --count 10 --name-template 'ci-{{.Index}}' --parallel 4 --power-on --wait-ip
+  vcli clone create my-vm my-clone --ttl 4h
+  vcli clone create golden-ubuntu my-vm --from-template
+  vcli clone create my-vm --count 20 --name-template 'worker-{{.Index}}' --mode instant --guestinfo 'hostname=worker-{{.Index}}'`,
+		Args: cobra.MaximumNArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
-			cfg, err := config.LoadFromEnv()
+			if len(args) > 0 {
+				vmName = args[0]
+			}
+			if len(args) > 1 {
+				cloneName = args[1]
+			}
 
-			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			names, err := cloneNames()
 			if err != nil {
 				return err
 			}
-
-			dcm := vmware.NewVMManager(c)
+			guestInfo, err := cloneGuestInfo()
 			if err != nil {
 				return err
 			}
+			if createTTL < 0 {
+				return fmt.Errorf("--ttl must not be negative")
+			}
+			if createMode != modeLinked && createMode != modeInstant {
+				return fmt.Errorf("invalid --mode %q (must be %s or %s)", createMode, modeLinked, modeInstant)
+			}
+			if createMode == modeInstant && createFromTemplate {
+				return fmt.Errorf("--mode instant cannot be used with --from-template: instant clones need a running source VM")
+			}
 
-			finder, err := vmware.NewDatacenterFinder(ctx, c.Client, global.DefaultDatacenterMoid)
+			cfg, err := config.LoadFromEnv()
 			if err != nil {
 				return err
 			}
 
-			vm, err := finder.FindVMByName(ctx, vmName)
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
 			if err != nil {
 				return err
 			}
 
-			snapshotRef, err := vm.FindSnapshot(ctx, snapshotName)
+			dcm := vmware.NewVMManager(c)
+
+			var (
+				vm          *object.VirtualMachine
+				snapshotRef *types.ManagedObjectReference
+				pool        *object.ResourcePool
+				folder      types.ManagedObjectReference
+			)
+			if createFromTemplate {
+				snapshotName = ""
+				vm, err = vsphere.ResolveTemplate(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
+				if err != nil {
+					return err
+				}
+
+				pool, err = vsphere.ResourcePool(ctx, c.Client, global.DefaultDatacenterMoid, global.DefaultClusterMoid, createResourcePool)
+				if err != nil {
+					return err
+				}
+			} else if createMode == modeInstant {
+				if err := vsphere.CheckInstantCloneSupport(c.Client); err != nil {
+					return err
+				}
+
+				snapshotName = ""
+				vm, err = vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
+				if err != nil {
+					return err
+				}
+			} else {
+				vm, err = vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
+				if err != nil {
+					return err
+				}
+
+				snapshotRef, err = vm.FindSnapshot(ctx, snapshotName)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Clones of every mode are created next to their source, where
+			// names are unique, so that is where they are looked up
+			props, err := vsphere.RetrieveVM(ctx, c.Client, vm.Reference(), []string{"parent"})
 			if err != nil {
 				return err
 			}
+			if props.Parent == nil {
+				return fmt.Errorf("%s has no parent folder", vmName)
+			}
+			folder = *props.Parent
+
+			attrs, err := vsphere.NewAttributes(ctx, c.Client)
+			if err != nil && createTTL > 0 {
+				return fmt.Errorf("--ttl requires custom attributes, which are unavailable: %w", err)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: custom attributes unavailable, clones will not be marked as created by vcli: %v\n", err)
+				attrs = nil
+			}
+
+			policy := workerpool.RetryPolicy{
+				Retries:   createRetries,
+				Delay:     5 * time.Second,
+				Retryable: vsphere.IsTransient,
+			}
+
+			results := make([]cloneResult, len(names))
+
+			runs := workerpool.Run(ctx, len(names), createParallel, func(ctx context.Context, i int) error {
+				res := &results[i]
+
+				var clone *object.VirtualMachine
+				attempt := 0
+				attempts, err := workerpool.Retry(ctx, policy, func(ctx context.Context) error {
+					attempt++
+					// A transient error may be reported after vSphere has
+					// created the clone; adopt it rather than retrying into
+					// a duplicate name
+					if attempt > 1 {
+						if existing, err := vsphere.FindChildVM(ctx, c.Client, folder, names[i]); err == nil {
+							clone = existing
+							return nil
+						}
+					}
+
+					var err error
+					switch {
+					case createFromTemplate:
+						clone, err = vsphere.CloneTemplate(ctx, c.Client, vm, names[i], pool.Reference())
+					case createMode == modeInstant:
+						clone, err = vsphere.InstantClone(ctx, c.Client, vm, names[i], vsphere.InstantCloneOptions{
+							RefreshMAC: createRefreshMAC,
+							GuestInfo:  guestInfo[i],
+						})
+					default:
+						err = dcm.CreateLinkedClone(ctx, vmware.CreateLinkedCloneRequest{
+							VmMoid:      vm.Reference().Value,
+							SnapshotRef: snapshotRef,
+							CloneName:   names[i],
+						})
+						if err == nil {
+							clone, err = vsphere.FindChildVM(ctx, c.Client, folder, names[i])
+						}
+					}
+					return err
+				})
+				res.Attempts = attempts
+				if err != nil {
+					return err
+				}
+				res.Moid = clone.Reference().Value
+
+				if attrs != nil {
+					p := vsphere.Provenance{
+						Source:    vmName,
+						Snapshot:  snapshotName,
+						CreatedAt: time.Now(),
+					}
+					if createTTL > 0 {
+						p.ExpiresAt = p.CreatedAt.Add(createTTL)
+					}
+					if err := vsphere.StampClone(ctx, attrs, clone.Reference(), p); err != nil {
+						return fmt.Errorf("record provenance: %w", err)
+					}
+				}
 
-			req := vmware.CreateLinkedCloneRequest{
-				VmMoid:      vm.Reference().Value,
-				SnapshotRef: snapshotRef,
-				CloneName:   cloneName,
+				if createPowerOn || createWaitIP {
+					if _, err := workerpool.Retry(ctx, policy, func(ctx context.Context) error {
+						return vsphere.PowerOn(ctx, clone)
+					}); err != nil {
+						return fmt.Errorf("power on: %w", err)
+					}
+					res.PoweredOn = true
+				}
+
+				if createWaitIP {
+					ip, err := vsphere.WaitForIP(ctx, clone, createIPTimeout)
+					if err != nil {
+						return err
+					}
+					res.IPAddress = ip
+				}
+
+				return nil
+			})
+
+			failed := 0
+			for i, run := range runs {
+				results[i].Name = names[i]
+				results[i].Duration = run.Duration.Round(time.Second).String()
+				results[i].Status = "ok"
+				if run.Err != nil {
+					failed++
+					results[i].Status = "failed"
+					results[i].Error = run.Err.Error()
+				}
 			}
 
-			if err := dcm.CreateLinkedClone(ctx, req); err != nil {
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"NAME", "MOID", "STATUS", "POWERED ON", "IP", "ATTEMPTS", "DURATION", "ERROR"}
+			if err := formatter.Print(results, headers, cloneResultRows); err != nil {
 				return err
 			}
 
-			fmt.Printf("Successfuly cloned VM %s\n", vm.Reference().Value)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d clones failed", failed, len(names))
+			}
 
 			return nil
-
 		},
 	}
 
 	cmd.Flags().StringVar(&vmName, "vmName", global.DefaultVmName, "from defaults.go if omitted")
 	cmd.Flags().StringVar(&snapshotName, "snapshotName", global.DefaultSnapshotName, "from defaults.go if omitted")
 	cmd.Flags().StringVar(&cloneName, "cloneName", global.DefaultClonedVmName, "from defaults.go if omitted")
+	cmd.Flags().IntVar(&createCount, "count", 1, "Number of clones to create")
+	cmd.Flags().StringVar(&createNameTemplate, "name-template", "", "Go template for clone names, required with --count > 1 (e.g. 'ci-{{.Index}}')")
+	cmd.Flags().IntVar(&createParallel, "parallel", 4, "Maximum number of clones created concurrently")
+	cmd.Flags().IntVar(&createRetries, "retries", 2, "Retries per clone on transient failures")
+	cmd.Flags().BoolVar(&createPowerOn, "power-on", false, "Power on each clone after it is created")
+	cmd.Flags().BoolVar(&createWaitIP, "wait-ip", false, "Power on each clone and wait until it reports an IP address")
+	cmd.Flags().DurationVar(&createIPTimeout, "ip-timeout", 5*time.Minute, "How long to wait for each clone's IP address")
+	cmd.Flags().BoolVar(&createFromTemplate, "from-template", false, "Treat the source as a template and create full clones")
+	cmd.Flags().StringVar(&createResourcePool, "resource-pool", "", "Resource pool name or path for --from-template clones (default: root pool of the default cluster)")
+	cmd.Flags().StringVar(&createMode, "mode", modeLinked, "Clone mode: linked or instant")
+	cmd.Flags().BoolVar(&createRefreshMAC, "refresh-mac", true, "Generate new MAC addresses for instant clones")
+	cmd.Flags().StringToStringVar(&createGuestInfo, "guestinfo", nil, "guestinfo.* variables to set on instant clones (key=value, value is a template like --name-template)")
+	cmd.Flags().DurationVar(&createTTL, "ttl", 0, "Expire the clones after this long so clone gc deletes them (e.g. 4h)")
+
 	return cmd
 }
+
+// cloneNames expands the clone name or name template into the list of clones to create
+func cloneNames() ([]string, error) {
+	if createCount < 1 {
+		return nil, fmt.Errorf("--count must be at least 1")
+	}
+	if createNameTemplate == "" {
+		if createCount > 1 {
+			return nil, fmt.Errorf("--name-template is required when --count is greater than 1")
+		}
+		return []string{cloneName}, nil
+	}
+
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(createNameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --name-template: %w", err)
+	}
+
+	names := make([]string, 0, createCount)
+	seen := make(map[string]bool, createCount)
+	for i := 1; i <= createCount; i++ {
+		var buf bytes.Buffer
+		data := nameTemplateData{Index: i, Source: vmName, Snapshot: snapshotName}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("invalid --name-template: %w", err)
+		}
+
+		name := buf.String()
+		if name == "" {
+			return nil, fmt.Errorf("--name-template produced an empty name for index %d", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("--name-template produced duplicate name %q; include {{.Index}}", name)
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// cloneGuestInfo renders the --guestinfo values into the variables of each clone
+func cloneGuestInfo() ([]map[string]string, error) {
+	tmpls := make(map[string]*template.Template, len(createGuestInfo))
+	for key, value := range createGuestInfo {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --guestinfo %s: %w", key, err)
+		}
+		tmpls[key] = tmpl
+	}
+
+	infos := make([]map[string]string, createCount)
+	for i := range infos {
+		data := nameTemplateData{Index: i + 1, Source: vmName, Snapshot: snapshotName}
+		info := make(map[string]string, len(tmpls))
+		for key, tmpl := range tmpls {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return nil, fmt.Errorf("invalid --guestinfo %s: %w", key, err)
+			}
+			info[key] = buf.String()
+		}
+		infos[i] = info
+	}
+
+	return infos, nil
+}
+
+func cloneResultRows(data interface{}) [][]string {
+	results := data.([]cloneResult)
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{
+			r.Name,
+			r.Moid,
+			r.Status,
+			output.FormatBool(r.PoweredOn),
+			r.IPAddress,
+			fmt.Sprintf("%d", r.Attempts),
+			r.Duration,
+			r.Error,
+		})
+	}
+	return rows
+}
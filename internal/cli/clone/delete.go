@@ -2,22 +2,79 @@ package clone
 
 import (
 	"fmt"
+	"os"
+	"strings"
+
 	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/internal/prompt"
 	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
 	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	deleteSelector string
+	deleteForce    bool
+	deleteYes      bool
+)
+
+// deleteTarget is a VM selected for deletion together with the result of its safety checks
+type deleteTarget struct {
+	vm         *object.VirtualMachine
+	name       string
+	poweredOn  bool
+	provenance string
+	refusal    string
+}
+
+// deleteResult is the per-VM outcome reported by clone delete
+type deleteResult struct {
+	Name       string `json:"name" yaml:"name"`
+	Moid       string `json:"moid" yaml:"moid"`
+	Provenance string `json:"provenance" yaml:"provenance"`
+	Status     string `json:"status" yaml:"status"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// deleteProps are the VM properties needed for the safety checks
+var deleteProps = []string{"name", "config.template", "config.hardware.device", "summary.config.vmPathName", "customValue", "runtime.powerState"}
+
 func newDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete <source-vm> <new-name>",
-		Short: "delete a linked clone of a VM",
-		Args:  cobra.NoArgs,
+		Use:   "delete [clone-name...]",
+		Short: "Delete linked clones of a VM",
+		Long: `Deletes one or more clones, selected by name or by a --selector glob.
+
+Only VMs created by vcli clone create or linked clones are deleted. Templates
+and other VMs are refused unless --force is given. Powered-on clones are
+powered off first. A confirmation prompt is shown unless --yes is given.
+
+Examples:
+  vcli clone delete my-clone
+  vcli clone delete ci-1 ci-2 ci-3 --yes
+  vcli clone delete --selector 'ci-*'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			names := args
+			if len(names) == 0 && deleteSelector == "" {
+				names = []string{cloneName}
+			}
+			if deleteSelector != "" {
+				if _, err := vsphere.MatchName(deleteSelector, ""); err != nil {
+					return fmt.Errorf("invalid --selector: %w", err)
+				}
+			}
+
 			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
 
 			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
 			if err != nil {
@@ -25,38 +82,158 @@ func newDeleteCmd() *cobra.Command {
 			}
 
 			dcm := vmware.NewVMManager(c)
-			if err != nil {
-				return err
+
+			var vms []*object.VirtualMachine
+			for _, name := range names {
+				vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, name)
+				if err != nil {
+					return err
+				}
+				vms = append(vms, vm)
 			}
 
-			finder, err := vmware.NewDatacenterFinder(ctx, c.Client, global.DefaultDatacenterMoid)
-			if err != nil {
-				return err
+			if deleteSelector != "" {
+				all, err := vsphere.ListVMs(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid), []string{"name"})
+				if err != nil {
+					return err
+				}
+				for _, vm := range all {
+					if ok, _ := vsphere.MatchName(deleteSelector, vm.Name); ok {
+						vms = append(vms, object.NewVirtualMachine(c.Client, vm.Reference()))
+					}
+				}
 			}
 
-			vm, err := finder.FindVMByName(ctx, cloneName)
+			attrs, err := vsphere.NewAttributes(ctx, c.Client)
 			if err != nil {
-				return err
+				attrs = nil
 			}
 
-			req := vmware.RemoveLinkedCloneRequest{
-				VmMoid: vm.Reference().Value,
+			var targets []deleteTarget
+			seen := make(map[string]bool)
+			for _, vm := range vms {
+				if seen[vm.Reference().Value] {
+					continue
+				}
+				seen[vm.Reference().Value] = true
+
+				props, err := vsphere.RetrieveVM(ctx, c.Client, vm.Reference(), deleteProps)
+				if err != nil {
+					return err
+				}
+
+				t := deleteTarget{
+					vm:        vm,
+					name:      props.Name,
+					poweredOn: props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff,
+				}
+
+				var values map[string]string
+				if attrs != nil {
+					values = attrs.Values(props.CustomValue)
+				}
+
+				switch {
+				case props.Config != nil && props.Config.Template:
+					t.provenance = "template"
+					t.refusal = "is a template"
+				case vsphere.IsVcliClone(values):
+					t.provenance = "vcli clone of " + values[vsphere.AttrSource]
+				case vsphere.IsLinkedClone(props):
+					t.provenance = "linked clone"
+				default:
+					t.provenance = "not a clone"
+					t.refusal = "was not created by vcli and is not a linked clone"
+				}
+
+				targets = append(targets, t)
+			}
+
+			if len(targets) == 0 {
+				return fmt.Errorf("no VMs matched selector %q", deleteSelector)
+			}
+
+			var refused []string
+			for _, t := range targets {
+				if t.refusal != "" {
+					refused = append(refused, fmt.Sprintf("%s %s", t.name, t.refusal))
+				}
+			}
+			if len(refused) > 0 && !deleteForce {
+				return fmt.Errorf("refusing to delete without --force:\n  %s", strings.Join(refused, "\n  "))
+			}
+
+			if !deleteYes {
+				fmt.Fprintln(os.Stderr, "The following VMs will be deleted:")
+				for _, t := range targets {
+					state := "powered off"
+					if t.poweredOn {
+						state = "will be powered off"
+					}
+					fmt.Fprintf(os.Stderr, "  %s (%s, %s)\n", t.name, t.provenance, state)
+				}
+
+				ok, err := prompt.Confirm(os.Stdin, os.Stderr, fmt.Sprintf("Delete %d VM(s)?", len(targets)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			results := make([]deleteResult, 0, len(targets))
+			failed := 0
+			for _, t := range targets {
+				res := deleteResult{
+					Name:       t.name,
+					Moid:       t.vm.Reference().Value,
+					Provenance: t.provenance,
+					Status:     "deleted",
+				}
+
+				err := vsphere.PowerOff(ctx, t.vm)
+				if err == nil {
+					err = dcm.RemoveLinkedClone(ctx, vmware.RemoveLinkedCloneRequest{
+						VmMoid: t.vm.Reference().Value,
+					})
+				}
+				if err != nil {
+					failed++
+					res.Status = "failed"
+					res.Error = err.Error()
+				}
+
+				results = append(results, res)
 			}
 
-			if err := dcm.RemoveLinkedClone(ctx, req); err != nil {
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"NAME", "MOID", "PROVENANCE", "STATUS", "ERROR"}
+			if err := formatter.Print(results, headers, deleteResultRows); err != nil {
 				return err
 			}
 
-			fmt.Printf("Successfuly delete VM %s\n", vm.Reference().Value)
+			if failed > 0 {
+				return fmt.Errorf("%d of %d deletions failed", failed, len(targets))
+			}
 
 			return nil
-
 		},
 	}
 
-	cmd.Flags().StringVar(&vmName, "vmName", global.DefaultVmName, "from defaults.go if omitted")
-	cmd.Flags().StringVar(&snapshotName, "snapshotName", global.DefaultSnapshotName, "from defaults.go if omitted")
-	cmd.Flags().StringVar(&cloneName, "cloneName", global.DefaultClonedVmName, "from defaults.go if omitted")
+	cmd.Flags().StringVar(&cloneName, "cloneName", global.DefaultClonedVmName, "Clone to delete when no names are given (from defaults.go if omitted)")
+	cmd.Flags().StringVar(&deleteSelector, "selector", "", "Delete every VM whose name matches this glob (e.g. 'ci-*')")
+	cmd.Flags().BoolVar(&deleteForce, "force", false, "Allow deleting templates and VMs that are not clones")
+	cmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip confirmation prompt")
 
 	return cmd
 }
+
+func deleteResultRows(data interface{}) [][]string {
+	results := data.([]deleteResult)
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		rows = append(rows, []string{r.Name, r.Moid, r.Provenance, r.Status, r.Error})
+	}
+	return rows
+}
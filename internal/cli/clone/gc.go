@@ -0,0 +1,180 @@
+package clone
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/asegev/vsphere-cli/pkg/workerpool"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcDryRun   bool
+	gcParallel int
+)
+
+// gcEntry is the per-clone outcome reported by clone gc
+type gcEntry struct {
+	Name       string    `json:"name" yaml:"name"`
+	Moid       string    `json:"moid" yaml:"moid"`
+	Source     string    `json:"source" yaml:"source"`
+	ExpiresAt  time.Time `json:"expiresAt" yaml:"expiresAt"`
+	ExpiredFor string    `json:"expiredFor" yaml:"expiredFor"`
+	Action     string    `json:"action" yaml:"action"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// gcReport is the full clone gc output
+type gcReport struct {
+	CheckedAt time.Time `json:"checkedAt" yaml:"checkedAt"`
+	DryRun    bool      `json:"dryRun" yaml:"dryRun"`
+	Scanned   int       `json:"scanned" yaml:"scanned"`
+	Expired   int       `json:"expired" yaml:"expired"`
+	Deleted   int       `json:"deleted" yaml:"deleted"`
+	Failed    int       `json:"failed" yaml:"failed"`
+	Clones    []gcEntry `json:"clones" yaml:"clones"`
+}
+
+func newGCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Delete clones whose TTL has expired",
+		Long: `Finds clones created with clone create --ttl whose expiry time has passed
+and deletes them, powering them off first if needed.
+
+Only VMs stamped by vcli are considered. Use --dry-run to list what would be
+deleted, and -o json for a machine-readable report suitable for cron jobs.
+
+Examples:
+  vcli clone gc --dry-run
+  vcli clone gc -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			dcm := vmware.NewVMManager(c)
+
+			attrs, err := vsphere.NewAttributes(ctx, c.Client)
+			if err != nil {
+				return fmt.Errorf("clone gc requires custom attributes: %w", err)
+			}
+
+			vms, err := vsphere.ListVMs(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid), []string{"name", "customValue"})
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			report := gcReport{
+				CheckedAt: now.UTC(),
+				DryRun:    gcDryRun,
+				Scanned:   len(vms),
+				Clones:    []gcEntry{},
+			}
+
+			var expired []*object.VirtualMachine
+			for _, vm := range vms {
+				values := attrs.Values(vm.CustomValue)
+				if !vsphere.IsVcliClone(values) {
+					continue
+				}
+				expiresAt, ok := vsphere.ExpiresAt(values)
+				if !ok || expiresAt.After(now) {
+					continue
+				}
+
+				report.Clones = append(report.Clones, gcEntry{
+					Name:       vm.Name,
+					Moid:       vm.Reference().Value,
+					Source:     values[vsphere.AttrSource],
+					ExpiresAt:  expiresAt,
+					ExpiredFor: now.Sub(expiresAt).Round(time.Second).String(),
+					Action:     "would delete",
+				})
+			}
+
+			sort.Slice(report.Clones, func(i, j int) bool {
+				return report.Clones[i].ExpiresAt.Before(report.Clones[j].ExpiresAt)
+			})
+			for _, e := range report.Clones {
+				expired = append(expired, object.NewVirtualMachine(c.Client, vsphere.VMRef(e.Moid)))
+			}
+			report.Expired = len(expired)
+
+			if !gcDryRun {
+				runs := workerpool.Run(ctx, len(expired), gcParallel, func(ctx context.Context, i int) error {
+					if err := vsphere.PowerOff(ctx, expired[i]); err != nil {
+						return err
+					}
+					return dcm.RemoveLinkedClone(ctx, vmware.RemoveLinkedCloneRequest{
+						VmMoid: expired[i].Reference().Value,
+					})
+				})
+
+				for i, run := range runs {
+					if run.Err != nil {
+						report.Failed++
+						report.Clones[i].Action = "failed"
+						report.Clones[i].Error = run.Err.Error()
+						continue
+					}
+					report.Deleted++
+					report.Clones[i].Action = "deleted"
+				}
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"NAME", "MOID", "SOURCE", "EXPIRES AT", "EXPIRED FOR", "ACTION", "ERROR"}
+			if err := formatter.Print(report, headers, gcReportRows); err != nil {
+				return err
+			}
+
+			if report.Failed > 0 {
+				return fmt.Errorf("%d of %d expired clones could not be deleted", report.Failed, report.Expired)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Report expired clones without deleting them")
+	cmd.Flags().IntVar(&gcParallel, "parallel", 4, "Maximum number of clones deleted concurrently")
+
+	return cmd
+}
+
+func gcReportRows(data interface{}) [][]string {
+	report := data.(gcReport)
+	rows := make([][]string, 0, len(report.Clones))
+	for _, e := range report.Clones {
+		rows = append(rows, []string{
+			e.Name,
+			e.Moid,
+			e.Source,
+			e.ExpiresAt.Local().Format(time.RFC3339),
+			e.ExpiredFor,
+			e.Action,
+			e.Error,
+		})
+	}
+	return rows
+}
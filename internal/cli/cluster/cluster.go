@@ -0,0 +1,21 @@
+package cluster
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewClusterCmd creates the cluster command
+func NewClusterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "List compute clusters",
+		Long: `List compute clusters with their DRS and HA settings and capacity.
+
+Available subcommands:
+  list  - List clusters with DRS/HA settings, hosts and resource totals`,
+	}
+
+	cmd.AddCommand(newListCmd())
+
+	return cmd
+}
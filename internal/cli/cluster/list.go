@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List clusters with DRS/HA settings, hosts and resource totals",
+		Long: `Lists the compute clusters of the datacenter with their DRS automation
+level, HA and admission control settings, host counts and CPU and memory
+demand against the cluster totals.
+
+HOSTS shows effective/total hosts: hosts in maintenance mode or
+disconnected do not count as effective, so they add no usable capacity.
+
+Examples:
+  vcli cluster list
+  vcli cluster list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			clusters, err := vsphere.ListClusters(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid))
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(clusters, []string{"NAME", "DRS", "HA", "HOSTS", "CPU", "MEMORY", "VMS"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, cl := range data.([]vsphere.ClusterSummary) {
+					drs := "off"
+					if cl.DRSEnabled {
+						drs = cl.DRSBehavior
+						if drs == "" {
+							drs = "on"
+						}
+					}
+					ha := "off"
+					if cl.HAEnabled {
+						ha = "on"
+						if cl.HAAdmission {
+							ha += " (admission control)"
+						}
+					}
+					rows = append(rows, []string{
+						cl.Name,
+						drs,
+						ha,
+						fmt.Sprintf("%d/%d", cl.NumEffective, cl.NumHosts),
+						output.FormatUsage(cl.CPU.Used, cl.CPU.Total, output.FormatMHz),
+						output.FormatUsage(cl.Memory.Used, cl.Memory.Total, output.FormatBytes),
+						fmt.Sprintf("%d", cl.NumVMs),
+					})
+				}
+				return rows
+			})
+		},
+	}
+}
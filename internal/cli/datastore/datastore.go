@@ -0,0 +1,59 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+// NewDatastoreCmd creates the datastore command
+func NewDatastoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datastore",
+		Short: "Browse and manage datastores",
+		Long: `List datastores, browse their contents and transfer files.
+
+Datastore paths are written <datastore>:/path (e.g. ssd-01:/isos/rhel.iso);
+the bracketed form "[ssd-01] isos/rhel.iso" is accepted as well.
+
+Available subcommands:
+  list      - List datastores with capacity and free space
+  ls        - List the contents of a datastore directory
+  upload    - Upload a local file to a datastore
+  download  - Download a file from a datastore
+  rm        - Delete files or directories from a datastore
+  mkdir     - Create a directory on a datastore
+  orphans   - Find VMDKs and snapshot files no registered VM uses`,
+	}
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newLsCmd())
+	cmd.AddCommand(newUploadCmd())
+	cmd.AddCommand(newDownloadCmd())
+	cmd.AddCommand(newRmCmd())
+	cmd.AddCommand(newMkdirCmd())
+	cmd.AddCommand(newOrphansCmd())
+
+	return cmd
+}
+
+// datastorePath resolves a "<datastore>:/path" argument to the datastore and
+// the path within it
+func datastorePath(ctx context.Context, c *vim25.Client, arg string) (*object.Datastore, string, error) {
+	name, p, err := vsphere.ParseDatastorePath(arg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ds, err := vsphere.NewFinder(c, global.DefaultDatacenterMoid).Datastore(ctx, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("datastore %q: %w", name, err)
+	}
+	return ds, p, nil
+}
@@ -0,0 +1,69 @@
+package datastore
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List datastores with capacity and free space",
+		Long: `Lists the datastores of the datacenter with their type, capacity, free
+space and whether they are currently accessible.
+
+Examples:
+  vcli datastore list
+  vcli datastore list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			stores, err := vsphere.ListDatastores(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid))
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(stores, []string{"NAME", "TYPE", "CAPACITY", "FREE", "FREE %", "ACCESSIBLE"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, ds := range data.([]vsphere.DatastoreSummary) {
+					free := "-"
+					if ds.CapacityBytes > 0 {
+						free = fmt.Sprintf("%.0f%%", float64(ds.FreeBytes)*100/float64(ds.CapacityBytes))
+					}
+					accessible := output.FormatBool(ds.Accessible)
+					if ds.MaintenanceMode != "" && ds.MaintenanceMode != "normal" {
+						accessible += " (" + ds.MaintenanceMode + ")"
+					}
+					rows = append(rows, []string{
+						ds.Name,
+						ds.Type,
+						output.FormatBytes(ds.CapacityBytes),
+						output.FormatBytes(ds.FreeBytes),
+						free,
+						accessible,
+					})
+				}
+				return rows
+			})
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package datastore
+
+import (
+	"time"
+
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newLsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ls <datastore>:/path",
+		Short: "List the contents of a datastore directory",
+		Long: `Lists a datastore directory with the type, size and modification time of
+each entry. Directories are listed first. When the path is a file, that file
+alone is described.
+
+Entry types: directory, disk, iso, config, log, nvram, snapshot, file.
+
+Examples:
+  vcli datastore ls ssd-01:/
+  vcli datastore ls ssd-01:/my-vm
+  vcli datastore ls "[ssd-01] isos"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			ds, p, err := datastorePath(ctx, c.Client, args[0])
+			if err != nil {
+				return err
+			}
+
+			files, err := vsphere.ListDatastoreDir(ctx, ds, p)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(files, []string{"NAME", "TYPE", "SIZE", "MODIFIED"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, f := range data.([]vsphere.DatastoreFile) {
+					size, modified := "-", "-"
+					if f.Type != "directory" {
+						size = output.FormatBytes(f.SizeBytes)
+					}
+					if f.Modified != nil {
+						modified = f.Modified.Local().Format(time.RFC3339)
+					}
+					rows = append(rows, []string{f.Name, f.Type, size, modified})
+				}
+				return rows
+			})
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package datastore
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var mkdirParents bool
+
+func newMkdirCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mkdir <datastore>:/path",
+		Short: "Create a directory on a datastore",
+		Long: `Creates a directory on a datastore. With -p, missing parent directories
+are created as well.
+
+Examples:
+  vcli datastore mkdir ssd-01:/isos
+  vcli datastore mkdir ssd-01:/imports/2024/q3 -p`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			ds, dir, err := datastorePath(ctx, c.Client, args[0])
+			if err != nil {
+				return err
+			}
+			if dir == "" {
+				return fmt.Errorf("%s is the datastore root", args[0])
+			}
+
+			if err := vsphere.MakeDatastoreDir(ctx, c.Client, global.DefaultDatacenterMoid, ds, dir, mkdirParents); err != nil {
+				return fmt.Errorf("failed to create %s: %w", ds.Path(dir), err)
+			}
+
+			fmt.Printf("Created %s\n", ds.Path(dir))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&mkdirParents, "parents", "p", false, "Create missing parent directories")
+
+	return cmd
+}
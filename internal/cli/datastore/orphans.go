@@ -0,0 +1,155 @@
+package datastore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/internal/prompt"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	orphansDelete bool
+	orphansYes    bool
+)
+
+func newOrphansCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orphans <datastore>[:/path]",
+		Short: "Find VMDKs and snapshot files no registered VM uses",
+		Long: `Walks a datastore (or one directory of it) and reports the files no
+registered VM or template references, as left behind by failed snapshot
+deletes, unregistered VMs and aborted copies:
+
+  disk            - A VMDK that is not attached to any VM
+  snapshot-delta  - A snapshot delta disk (vm-000001.vmdk, vm-delta.vmdk)
+                    that is not part of any VM's disk chain
+  snapshot-state  - A .vmsn file of a snapshot that no longer exists
+
+A file counts as referenced when it appears in the file layout of a VM
+with files on the datastore, or anywhere in the backing chain of one of its
+disks. Directories of inaccessible VMs, whose files vSphere cannot report,
+are skipped and listed on stderr.
+
+Hidden directories, First Class Disks (fcd) and content library items
+(contentlib-*) are not scanned, since their disks are not attached to VMs by
+design. vSAN and VVol datastores are not supported.
+
+With --delete, the orphans are deleted after a confirmation prompt (unless
+--yes is given). Snapshot deltas are deleted before the disks they may
+depend on.
+
+Examples:
+  vcli datastore orphans ssd-01
+  vcli datastore orphans ssd-01:/old-vm
+  vcli datastore orphans ssd-01 -o json
+  vcli datastore orphans ssd-01 --delete`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			arg := args[0]
+			if !strings.ContainsAny(arg, ":[") {
+				arg += ":/"
+			}
+			ds, dir, err := datastorePath(ctx, c.Client, arg)
+			if err != nil {
+				return err
+			}
+
+			scan, err := vsphere.FindOrphans(ctx, c.Client, ds, dir)
+			if err != nil {
+				return fmt.Errorf("failed to scan %s: %w", ds.Path(dir), err)
+			}
+
+			for _, d := range scan.SkippedDirs {
+				fmt.Fprintf(os.Stderr, "Warning: skipped %s (its VM is inaccessible)\n", d)
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			if err := formatter.Print(scan, []string{"PATH", "KIND", "SIZE", "MODIFIED"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, o := range data.(*vsphere.OrphanScan).Orphans {
+					modified := "-"
+					if o.Modified != nil {
+						modified = o.Modified.Local().Format(time.RFC3339)
+					}
+					rows = append(rows, []string{o.Path, o.Kind, output.FormatBytes(o.SizeBytes), modified})
+				}
+				return rows
+			}); err != nil {
+				return err
+			}
+
+			var total int64
+			for _, o := range scan.Orphans {
+				total += o.SizeBytes
+			}
+			fmt.Fprintf(os.Stderr, "%d orphaned file(s), %s\n", len(scan.Orphans), output.FormatBytes(total))
+
+			if !orphansDelete || len(scan.Orphans) == 0 {
+				return nil
+			}
+
+			if !orphansYes {
+				ok, err := prompt.Confirm(os.Stdin, os.Stderr, fmt.Sprintf("Delete %d orphaned file(s) (%s)?", len(scan.Orphans), output.FormatBytes(total)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			// Children before parents: deltas first, deepest first
+			orphans := scan.Orphans
+			sort.SliceStable(orphans, func(i, j int) bool {
+				di := orphans[i].Kind == vsphere.OrphanSnapshotDelta
+				dj := orphans[j].Kind == vsphere.OrphanSnapshotDelta
+				if di != dj {
+					return di
+				}
+				return orphans[i].Path > orphans[j].Path
+			})
+
+			var failed int
+			for _, o := range orphans {
+				if err := vsphere.RemoveDatastoreFile(ctx, c.Client, global.DefaultDatacenterMoid, ds, o.Path); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to delete %s: %v\n", o.Path, err)
+					failed++
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "Deleted %s\n", o.Path)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to delete %d of %d orphaned files", failed, len(orphans))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&orphansDelete, "delete", false, "Delete the orphaned files after confirmation")
+	cmd.Flags().BoolVarP(&orphansYes, "yes", "y", false, "Skip confirmation prompt")
+
+	return cmd
+}
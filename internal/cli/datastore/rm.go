@@ -0,0 +1,109 @@
+package datastore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/internal/prompt"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+
+	"github.com/spf13/cobra"
+)
+
+var rmYes bool
+
+func newRmCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm <datastore>:/path...",
+		Short: "Delete files or directories from a datastore",
+		Long: `Deletes files or directories from a datastore. Directories are deleted
+with their contents. Virtual disks (.vmdk) are deleted through the virtual
+disk manager, so their flat and delta extents are removed with them.
+
+Files that belong to a registered VM are not checked; deleting them breaks
+that VM. A confirmation prompt is shown unless --yes is given.
+
+Examples:
+  vcli datastore rm ssd-01:/isos/old.iso
+  vcli datastore rm ssd-01:/imports/disk.vmdk ssd-01:/imports/tmp --yes`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			type target struct {
+				ds   *object.Datastore
+				path string
+				file vsphere.DatastoreFile
+			}
+
+			targets := make([]target, 0, len(args))
+			for _, arg := range args {
+				ds, p, err := datastorePath(ctx, c.Client, arg)
+				if err != nil {
+					return err
+				}
+				if p == "" {
+					return fmt.Errorf("refusing to delete the root of datastore %s", ds.Name())
+				}
+				fi, err := ds.Stat(ctx, p)
+				if err != nil {
+					return fmt.Errorf("%s: %w", ds.Path(p), err)
+				}
+				targets = append(targets, target{ds: ds, path: p, file: vsphere.DatastoreFileInfo(ds, p, fi)})
+			}
+
+			if !rmYes {
+				fmt.Fprintln(os.Stderr, "The following will be deleted:")
+				for _, t := range targets {
+					if t.file.Type == "directory" {
+						fmt.Fprintf(os.Stderr, "  %s (directory, with its contents)\n", t.file.Path)
+					} else {
+						fmt.Fprintf(os.Stderr, "  %s (%s, %s)\n", t.file.Path, t.file.Type, output.FormatBytes(t.file.SizeBytes))
+					}
+				}
+
+				ok, err := prompt.Confirm(os.Stdin, os.Stderr, fmt.Sprintf("Delete %d item(s)?", len(targets)))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			var failed int
+			for _, t := range targets {
+				if err := vsphere.RemoveDatastoreFile(ctx, c.Client, global.DefaultDatacenterMoid, t.ds, t.path); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to delete %s: %v\n", t.file.Path, err)
+					failed++
+					continue
+				}
+				fmt.Printf("Deleted %s\n", t.file.Path)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to delete %d of %d items", failed, len(targets))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&rmYes, "yes", "y", false, "Skip confirmation prompt")
+
+	return cmd
+}
@@ -0,0 +1,175 @@
+package datastore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	uploadResume     bool
+	uploadVerify     bool
+	uploadChecksum   string
+	downloadResume   bool
+	downloadChecksum string
+)
+
+func newUploadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload <local-file> <datastore>:/path",
+		Short: "Upload a local file to a datastore",
+		Long: `Uploads a local file through the datastore HTTP file transfer endpoint,
+printing progress to stderr. When the destination is a directory (ends with a
+slash or is the datastore root), the local file name is kept.
+
+The transfer endpoint cannot append to a file, so an interrupted upload is
+restarted from the beginning. --resume skips the upload when the remote file
+already exists with the same size and SHA-256, which reads it back first.
+
+--checksum checks the SHA-256 of the local file before uploading it, and
+--verify reads the file back from the datastore after the upload and
+compares its SHA-256 with the local file.
+
+Examples:
+  vcli datastore upload rhel-9.4.iso ssd-01:/isos/
+  vcli datastore upload disk.vmdk ssd-01:/imports/disk.vmdk --verify
+  vcli datastore upload rhel-9.4.iso ssd-01:/isos/ --checksum sha256:3f1c...`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			ds, remote, err := datastorePath(ctx, c.Client, args[1])
+			if err != nil {
+				return err
+			}
+			if remote == "" || strings.HasSuffix(args[1], "/") {
+				remote = path.Join(remote, filepath.Base(args[0]))
+			}
+
+			sink := vsphere.NewProgress(os.Stderr, "Uploading "+filepath.Base(args[0]))
+			res, err := vsphere.UploadDatastoreFile(ctx, ds, args[0], remote, vsphere.TransferOptions{
+				Resume:   uploadResume,
+				Verify:   uploadVerify,
+				Checksum: uploadChecksum,
+				Progress: sink,
+			})
+			sink.Wait()
+			if err != nil {
+				return fmt.Errorf("failed to upload %s: %w", args[0], err)
+			}
+
+			return printTransfer(cmd, res)
+		},
+	}
+
+	cmd.Flags().BoolVar(&uploadResume, "resume", false, "Skip the upload if the remote file already has the same size and SHA-256")
+	cmd.Flags().BoolVar(&uploadVerify, "verify", false, "Read the file back after uploading and compare SHA-256 checksums")
+	cmd.Flags().StringVar(&uploadChecksum, "checksum", "", "Expected SHA-256 of the local file (hex, optionally prefixed with sha256:)")
+
+	return cmd
+}
+
+func newDownloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download <datastore>:/path [local-file]",
+		Short: "Download a file from a datastore",
+		Long: `Downloads a datastore file through the HTTP file transfer endpoint,
+printing progress to stderr. The local file defaults to the remote file name
+in the current directory; when it is an existing directory, the remote file
+name is kept.
+
+--resume continues a partial local file with a range request instead of
+starting over. A partial file is left in place when a download is
+interrupted, so it can be resumed.
+
+The SHA-256 of the downloaded file is always printed; --checksum fails the
+download when it does not match.
+
+Examples:
+  vcli datastore download ssd-01:/my-vm/my-vm.vmx
+  vcli datastore download ssd-01:/isos/rhel-9.4.iso /tmp/ --resume
+  vcli datastore download "[ssd-01] my-vm/my-vm-flat.vmdk" disk.img --checksum sha256:3f1c...`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			ds, remote, err := datastorePath(ctx, c.Client, args[0])
+			if err != nil {
+				return err
+			}
+			if remote == "" {
+				return fmt.Errorf("%s is the datastore root, not a file", args[0])
+			}
+
+			local := path.Base(remote)
+			if len(args) == 2 {
+				local = args[1]
+				if fi, err := os.Stat(local); err == nil && fi.IsDir() {
+					local = filepath.Join(local, path.Base(remote))
+				}
+			}
+
+			sink := vsphere.NewProgress(os.Stderr, "Downloading "+path.Base(remote))
+			res, err := vsphere.DownloadDatastoreFile(ctx, ds, remote, local, vsphere.TransferOptions{
+				Resume:   downloadResume,
+				Checksum: downloadChecksum,
+				Progress: sink,
+			})
+			sink.Wait()
+			if err != nil {
+				return fmt.Errorf("failed to download %s: %w", args[0], err)
+			}
+
+			return printTransfer(cmd, res)
+		},
+	}
+
+	cmd.Flags().BoolVar(&downloadResume, "resume", false, "Continue a partial local file instead of starting over")
+	cmd.Flags().StringVar(&downloadChecksum, "checksum", "", "Expected SHA-256 of the file (hex, optionally prefixed with sha256:)")
+
+	return cmd
+}
+
+func printTransfer(cmd *cobra.Command, res *vsphere.TransferResult) error {
+	formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+	return formatter.Print(res, []string{"SOURCE", "DESTINATION", "SIZE", "TRANSFERRED", "SHA256", "VERIFIED"}, func(data interface{}) [][]string {
+		r := data.(*vsphere.TransferResult)
+		return [][]string{{
+			r.Source,
+			r.Destination,
+			output.FormatBytes(r.SizeBytes),
+			output.FormatBytes(r.TransferredBytes),
+			r.SHA256,
+			output.FormatBool(r.Verified),
+		}}
+	})
+}
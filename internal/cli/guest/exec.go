@@ -0,0 +1,70 @@
+package guest
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+
+	"github.com/spf13/cobra"
+)
+
+// ExitCodeError carries the exit code of a guest command so vcli can exit with it
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("guest command exited with code %d", e.Code)
+}
+
+// ExitCode returns the guest command's exit code
+func (e *ExitCodeError) ExitCode() int {
+	return e.Code
+}
+
+func newExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec <vm> -- <command> [args...]",
+		Short: "Run a command in the guest",
+		Long: `Runs a command in the guest through the Guest Operations API and streams its
+stdout and stderr while it runs, about once a second. vcli exits with the
+command's exit code.
+
+Commands without an absolute path run through /bin/bash -c on Linux guests
+and through cmd.exe /c on Windows guests. Each argument is quoted and reaches
+the command unchanged; for pipes or other shell syntax, run a shell
+explicitly. Interrupting vcli terminates the guest command.
+
+Examples:
+  vcli guest exec my-vm -- uname -a
+  vcli guest exec my-vm -- /usr/bin/systemctl is-active sshd
+  vcli guest exec my-vm -- bash -c 'journalctl -u sshd | tail -n 20'
+  vcli guest exec win-vm -- ipconfig /all`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash != 1 {
+				return fmt.Errorf("separate the command from the VM name with --")
+			}
+
+			ctx := cmd.Context()
+
+			tc, err := connectGuest(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			code, err := vsphere.GuestExec(ctx, tc, args[1:], os.Stdout, os.Stderr)
+			if err != nil {
+				return err
+			}
+			if code != 0 {
+				cmd.SilenceUsage = true
+				return &ExitCodeError{Code: code}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,84 @@
+package guest
+
+import (
+	"context"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/guest/toolbox"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	guestUsername string
+	guestPassword string
+)
+
+// NewGuestCmd creates the guest command
+func NewGuestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "guest",
+		Short: "Inspect and operate guest operating systems",
+		Long: `Inspect and operate guest operating systems through VMware Tools.
+
+Guest operations (exec, upload, download, ps, kill) authenticate inside the
+guest with VCLI_GUEST_USERNAME and VCLI_GUEST_PASSWORD, or with the
+--guest-username and --guest-password flags.
+
+Available subcommands:
+  info      - Display guest OS, Tools, network and filesystem information
+  wait-ip   - Wait until the guest reports an IP address
+  exec      - Run a command in the guest
+  upload    - Copy a local file into the guest
+  download  - Copy a file out of the guest
+  ps        - List guest processes
+  kill      - Terminate guest processes`,
+	}
+
+	cmd.PersistentFlags().StringVar(&guestUsername, "guest-username", "", "Guest OS username (overrides VCLI_GUEST_USERNAME)")
+	cmd.PersistentFlags().StringVar(&guestPassword, "guest-password", "", "Guest OS password (overrides VCLI_GUEST_PASSWORD)")
+
+	cmd.AddCommand(newInfoCmd())
+	cmd.AddCommand(newWaitIPCmd())
+	cmd.AddCommand(newExecCmd())
+	cmd.AddCommand(newUploadCmd())
+	cmd.AddCommand(newDownloadCmd())
+	cmd.AddCommand(newPsCmd())
+	cmd.AddCommand(newKillCmd())
+
+	return cmd
+}
+
+// connectGuest finds a VM and opens a Guest Operations client for it
+func connectGuest(ctx context.Context, vmName string) (*toolbox.Client, error) {
+	creds := config.LoadGuestFromEnv()
+	if guestUsername != "" {
+		creds.Username = guestUsername
+	}
+	if guestPassword != "" {
+		creds.Password = guestPassword
+	}
+	if err := creds.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
+	if err != nil {
+		return nil, err
+	}
+
+	return vsphere.NewGuestClient(ctx, c.Client, vm, creds.Username, creds.Password)
+}
@@ -0,0 +1,106 @@
+package guest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newInfoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "info <vm>",
+		Short: "Display guest OS, Tools, network and filesystem information",
+		Long: `Displays what VMware Tools reports about the guest operating system.
+
+Information displayed:
+  - VMware Tools: Running status, version, upgrade status
+  - Guest OS: Family, full name, hostname
+  - Network: IP addresses of every NIC
+  - Filesystems: Mount points with capacity and free space
+
+Examples:
+  vcli guest info my-vm
+  vcli guest info my-vm -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			report, err := vsphere.BuildGuestReport(ctx, vm)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.PrintReport(report, guestSections)
+		},
+	}
+
+	return cmd
+}
+
+// guestSections renders a guest report as the sectioned text shown in table mode
+func guestSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.GuestReport)
+
+	tools := output.Section{Title: "VMware Tools"}
+	tools.AddField("Status", output.OrNone(r.Tools.RunningStatus))
+	tools.AddField("Version", output.OrNone(r.Tools.Version))
+	tools.AddField("Version Status", output.OrNone(r.Tools.VersionStatus))
+
+	guest := output.Section{Title: "Guest OS"}
+	guest.AddField("Power State", r.PowerState)
+	guest.AddField("Family", output.OrNone(r.GuestFamily))
+	guest.AddField("Full Name", output.OrNone(r.GuestFullName))
+	guest.AddField("Hostname", output.OrNone(r.Hostname))
+	guest.AddField("Primary IP", output.OrNone(r.IPAddress))
+
+	network := output.Section{Title: "Network"}
+	if len(r.NICs) == 0 {
+		network.AddField("NICs", "<none>")
+	}
+	for i, n := range r.NICs {
+		network.AddField(fmt.Sprintf("NIC %d", i+1), fmt.Sprintf("%s (%s)", output.OrNone(n.Network), n.MAC))
+		network.AddNested("Connected", output.FormatBool(n.Connected))
+		network.AddNested("IP", output.OrNone(strings.Join(n.IPs, ", ")))
+	}
+
+	disks := output.Section{Title: "Filesystems"}
+	if len(r.Disks) == 0 {
+		disks.AddField("Mounts", "<none>")
+	}
+	for _, d := range r.Disks {
+		used := d.CapacityBytes - d.FreeBytes
+		summary := fmt.Sprintf("%s free of %s", output.FormatBytes(d.FreeBytes), output.FormatBytes(d.CapacityBytes))
+		if d.CapacityBytes > 0 {
+			summary += fmt.Sprintf(" (%.0f%% used)", float64(used)*100/float64(d.CapacityBytes))
+		}
+		if d.FilesystemType != "" {
+			summary += ", " + d.FilesystemType
+		}
+		disks.AddField(d.Path, summary)
+	}
+
+	return "Guest: " + r.VM, []output.Section{tools, guest, network, disks}
+}
@@ -0,0 +1,127 @@
+package guest
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+
+	"github.com/spf13/cobra"
+)
+
+var psAll bool
+
+func newPsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ps <vm>",
+		Short: "List guest processes",
+		Long: `Lists the processes running in the guest. With --all, processes started
+through the Guest Operations API that exited recently are included with
+their exit codes.
+
+Examples:
+  vcli guest ps my-vm
+  vcli guest ps my-vm -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			tc, err := connectGuest(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			procs, err := vsphere.GuestProcesses(ctx, tc, nil)
+			if err != nil {
+				return err
+			}
+
+			if !psAll {
+				running := procs[:0]
+				for _, p := range procs {
+					if !p.Exited {
+						running = append(running, p)
+					}
+				}
+				procs = running
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"PID", "OWNER", "STARTED", "STATUS", "COMMAND"}
+			return formatter.Print(procs, headers, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, p := range data.([]vsphere.GuestProcess) {
+					status := "running"
+					if p.Exited {
+						status = fmt.Sprintf("exited (%d)", p.ExitCode)
+					}
+					command := p.CmdLine
+					if command == "" {
+						command = p.Name
+					}
+					rows = append(rows, []string{
+						strconv.FormatInt(p.PID, 10),
+						p.Owner,
+						p.StartTime.Local().Format(time.RFC3339),
+						status,
+						command,
+					})
+				}
+				return rows
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&psAll, "all", false, "Include recently exited processes")
+
+	return cmd
+}
+
+func newKillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kill <vm> <pid...>",
+		Short: "Terminate guest processes",
+		Long: `Terminates one or more processes in the guest by PID.
+
+Examples:
+  vcli guest kill my-vm 4242
+  vcli guest kill my-vm 4242 4243`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var pids []int64
+			for _, arg := range args[1:] {
+				pid, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid PID %q", arg)
+				}
+				pids = append(pids, pid)
+			}
+
+			tc, err := connectGuest(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, pid := range pids {
+				if err := vsphere.GuestKill(ctx, tc, pid); err != nil {
+					fmt.Printf("Failed to terminate %d: %v\n", pid, err)
+					failed++
+					continue
+				}
+				fmt.Printf("Terminated %d\n", pid)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d processes could not be terminated", failed, len(pids))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,152 @@
+package guest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+
+	"github.com/spf13/cobra"
+)
+
+var uploadForce bool
+
+func newUploadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload <vm> <local-file> <guest-path>",
+		Short: "Copy a local file into the guest",
+		Long: `Copies a local file to a path in the guest through the Guest Operations API.
+Use "-" as the local file to read from stdin.
+
+Examples:
+  vcli guest upload my-vm ./setup.sh /tmp/setup.sh
+  vcli guest upload my-vm ./app.conf /etc/app.conf --force
+  echo hello | vcli guest upload my-vm - /tmp/hello.txt`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var src *os.File
+			if args[1] == "-" {
+				// The transfer needs the length up front, which a pipe
+				// does not have
+				tmp, err := bufferStdin()
+				if err != nil {
+					return err
+				}
+				defer os.Remove(tmp.Name())
+				defer tmp.Close()
+				src = tmp
+			} else {
+				f, err := os.Open(args[1])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				src = f
+			}
+
+			info, err := src.Stat()
+			if err != nil {
+				return err
+			}
+			size := info.Size()
+
+			tc, err := connectGuest(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := vsphere.GuestUpload(ctx, tc, src, size, args[2], uploadForce); err != nil {
+				return fmt.Errorf("failed to upload %s: %w", args[1], err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Uploaded %s to %s:%s\n", output.FormatBytes(size), args[0], args[2])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&uploadForce, "force", false, "Overwrite the guest file if it exists")
+
+	return cmd
+}
+
+func newDownloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download <vm> <guest-path> <local-file>",
+		Short: "Copy a file out of the guest",
+		Long: `Copies a file from the guest to a local path through the Guest Operations API.
+Use "-" as the local file to write to stdout.
+
+Examples:
+  vcli guest download my-vm /var/log/syslog ./syslog
+  vcli guest download my-vm /etc/os-release -`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			tc, err := connectGuest(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			if args[2] == "-" {
+				if _, err := vsphere.GuestDownload(ctx, tc, args[1], os.Stdout); err != nil {
+					return fmt.Errorf("failed to download %s: %w", args[1], err)
+				}
+				return nil
+			}
+
+			// Download next to the destination and rename it into place
+			// on success, so a failed transfer leaves an existing file alone
+			f, err := os.CreateTemp(filepath.Dir(args[2]), "."+filepath.Base(args[2])+".*")
+			if err != nil {
+				return err
+			}
+			defer os.Remove(f.Name())
+			// CreateTemp uses 0600; match what os.Create would have made
+			if err := f.Chmod(0o644); err != nil {
+				f.Close()
+				return err
+			}
+
+			n, err := vsphere.GuestDownload(ctx, tc, args[1], f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to download %s: %w", args[1], err)
+			}
+			if err := os.Rename(f.Name(), args[2]); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Downloaded %s from %s:%s\n", output.FormatBytes(n), args[0], args[1])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// bufferStdin copies stdin to a temporary file and rewinds it
+func bufferStdin() (*os.File, error) {
+	f, err := os.CreateTemp("", "vcli-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
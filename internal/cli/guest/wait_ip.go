@@ -0,0 +1,68 @@
+package guest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var waitIPTimeout time.Duration
+
+func newWaitIPCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait-ip <vm>",
+		Short: "Wait until the guest reports an IP address",
+		Long: `Blocks until VMware Tools reports an IPv4 address for the VM, then prints it.
+Fails immediately if the VM is powered off, or once --timeout expires.
+
+Examples:
+  vcli guest wait-ip my-vm
+  vcli guest wait-ip my-vm --timeout 10m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			state, err := vm.PowerState(ctx)
+			if err != nil {
+				return err
+			}
+			if state != types.VirtualMachinePowerStatePoweredOn {
+				return fmt.Errorf("VM %s is %s", args[0], state)
+			}
+
+			ip, err := vsphere.WaitForIP(ctx, vm, waitIPTimeout)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(ip)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&waitIPTimeout, "timeout", 5*time.Minute, "How long to wait for an IP address")
+
+	return cmd
+}
@@ -0,0 +1,24 @@
+package host
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewHostCmd creates the host command
+func NewHostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "host",
+		Short: "List ESXi hosts and manage maintenance mode",
+		Long: `List ESXi hosts with their state and capacity, and move them in and out
+of maintenance mode.
+
+Available subcommands:
+  list         - List hosts with state, usage and version
+  maintenance  - Enter or exit maintenance mode`,
+	}
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newMaintenanceCmd())
+
+	return cmd
+}
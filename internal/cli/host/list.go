@@ -0,0 +1,88 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var listCluster string
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List hosts with state, usage and version",
+		Long: `Lists the ESXi hosts of the datacenter with their cluster, connection and
+maintenance state, CPU and memory usage against capacity, ESXi version and
+the number of VMs registered on them.
+
+Examples:
+  vcli host list
+  vcli host list --cluster prod-a
+  vcli host list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			root := vsphere.DatacenterRef(global.DefaultDatacenterMoid)
+			if listCluster != "" {
+				cluster, err := vsphere.NewFinder(c.Client, global.DefaultDatacenterMoid).ClusterComputeResource(ctx, listCluster)
+				if err != nil {
+					return fmt.Errorf("cluster %q: %w", listCluster, err)
+				}
+				root = cluster.Reference()
+			}
+
+			hosts, err := vsphere.ListHosts(ctx, c.Client, root)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(hosts, []string{"NAME", "CLUSTER", "STATE", "MAINTENANCE", "CPU", "MEMORY", "VERSION", "VMS"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, h := range data.([]vsphere.HostSummary) {
+					cluster := h.Cluster
+					if cluster == "" {
+						cluster = "-"
+					}
+					version := h.Version
+					if h.Build != "" {
+						version += " (" + h.Build + ")"
+					}
+					rows = append(rows, []string{
+						h.Name,
+						cluster,
+						h.ConnectionState,
+						output.FormatBool(h.MaintenanceMode),
+						output.FormatUsage(h.CPU.Used, h.CPU.Total, output.FormatMHz),
+						output.FormatUsage(h.Memory.Used, h.Memory.Total, output.FormatBytes),
+						version,
+						fmt.Sprintf("%d", h.NumVMs),
+					})
+				}
+				return rows
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&listCluster, "cluster", "", "Only list hosts of this cluster")
+
+	return cmd
+}
@@ -0,0 +1,180 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	enterTimeout      time.Duration
+	enterEvacuateAll  bool
+	enterPollInterval time.Duration
+	exitTimeout       time.Duration
+)
+
+func newMaintenanceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Enter or exit maintenance mode",
+		Long: `Moves a host in and out of maintenance mode.
+
+Available subcommands:
+  enter  - Evacuate a host and put it into maintenance mode
+  exit   - Take a host out of maintenance mode`,
+	}
+
+	cmd.AddCommand(newEnterCmd())
+	cmd.AddCommand(newExitCmd())
+
+	return cmd
+}
+
+func newEnterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enter <host>",
+		Short: "Evacuate a host and put it into maintenance mode",
+		Long: `Puts a host into maintenance mode and waits until it is evacuated. The
+powered-on VMs still on the host are reported on stderr as they leave.
+
+In a cluster with DRS fully automated, vCenter migrates the powered-on VMs
+away. Otherwise they have to be migrated or powered off by hand (e.g. with
+'vcli vm migrate') before the host enters maintenance mode; a warning is
+printed when that is the case.
+
+--evacuate-powered-off also moves powered-off and suspended VMs off the host.
+When --timeout passes before the host is evacuated, vCenter fails the task
+and the host stays out of maintenance mode.
+
+Examples:
+  vcli host maintenance enter esx-03.example.com
+  vcli host maintenance enter esx-03 --timeout 30m --evacuate-powered-off`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			host, name, inMaintenance, err := findHost(ctx, c.Client, args[0])
+			if err != nil {
+				return err
+			}
+			if inMaintenance {
+				fmt.Printf("Host %s is already in maintenance mode\n", name)
+				return nil
+			}
+
+			blocker, err := vsphere.EvacuationBlocker(ctx, c.Client, host)
+			if err != nil {
+				return err
+			}
+			if blocker != "" {
+				fmt.Fprintf(os.Stderr, "Warning: %s; powered-on VMs must be moved off %s by hand\n", blocker, name)
+			}
+
+			fmt.Fprintf(os.Stderr, "Entering maintenance mode on %s...\n", name)
+			err = vsphere.EnterMaintenance(ctx, c.Client, host, vsphere.MaintenanceOptions{
+				Timeout:            enterTimeout,
+				EvacuatePoweredOff: enterEvacuateAll,
+				PollInterval:       enterPollInterval,
+			}, func(vms []string) {
+				if len(vms) == 0 {
+					fmt.Fprintln(os.Stderr, "All powered-on VMs evacuated")
+					return
+				}
+				fmt.Fprintf(os.Stderr, "Waiting for %d VM(s) to evacuate: %s\n", len(vms), strings.Join(vms, ", "))
+			})
+			if err != nil {
+				return fmt.Errorf("failed to enter maintenance mode on %s: %w", name, err)
+			}
+
+			fmt.Printf("Host %s is in maintenance mode\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&enterTimeout, "timeout", 0, "Fail if the host is not evacuated in time (0 waits forever)")
+	cmd.Flags().BoolVar(&enterEvacuateAll, "evacuate-powered-off", false, "Also move powered-off and suspended VMs off the host")
+	cmd.Flags().DurationVar(&enterPollInterval, "poll-interval", 5*time.Second, "How often to report the VMs left on the host")
+
+	return cmd
+}
+
+func newExitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exit <host>",
+		Short: "Take a host out of maintenance mode",
+		Long: `Takes a host out of maintenance mode. In a DRS cluster, VMs are moved back
+onto the host by DRS over time.
+
+Examples:
+  vcli host maintenance exit esx-03.example.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			host, name, inMaintenance, err := findHost(ctx, c.Client, args[0])
+			if err != nil {
+				return err
+			}
+			if !inMaintenance {
+				fmt.Printf("Host %s is not in maintenance mode\n", name)
+				return nil
+			}
+
+			if err := vsphere.ExitMaintenance(ctx, host, exitTimeout); err != nil {
+				return fmt.Errorf("failed to exit maintenance mode on %s: %w", name, err)
+			}
+
+			fmt.Printf("Host %s exited maintenance mode\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&exitTimeout, "timeout", 0, "Fail if the host has not exited maintenance mode in time (0 waits forever)")
+
+	return cmd
+}
+
+// findHost resolves a host argument and reads its maintenance state
+func findHost(ctx context.Context, c *vim25.Client, arg string) (*object.HostSystem, string, bool, error) {
+	host, err := vsphere.NewFinder(c, global.DefaultDatacenterMoid).HostSystem(ctx, arg)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("host %q: %w", arg, err)
+	}
+
+	name, inMaintenance, err := vsphere.HostMaintenanceState(ctx, c, host)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return host, name, inMaintenance, nil
+}
@@ -0,0 +1,73 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+func newClusterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster <cluster-name>",
+		Short: "Display cluster information",
+		Long: `Displays information about a compute cluster.
+
+Information displayed:
+  - General: Health, DRS and HA configuration
+  - Capacity: Hosts, CPU and memory demand against capacity
+  - Members: Hosts, datastores, networks and VM count
+
+Examples:
+  vcli inspect cluster prod-cluster
+  vcli inspect cluster prod-cluster -o yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, args[0], func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error) {
+				cluster, err := finder.ClusterComputeResource(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return vsphere.BuildClusterReport(ctx, c, cluster)
+			}, clusterSections)
+		},
+	}
+
+	return cmd
+}
+
+// clusterSections renders a cluster report as sectioned text
+func clusterSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.ClusterReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Health", r.OverallStatus)
+	general.AddField("DRS", output.FormatBool(r.DRSEnabled))
+	if r.DRSEnabled {
+		general.AddNested("Automation", r.DRSBehavior)
+	}
+	general.AddField("HA", output.FormatBool(r.HAEnabled))
+	if r.HAEnabled {
+		general.AddNested("Admission Control", output.FormatBool(r.HAAdmission))
+	}
+	general.AddField("MOID", r.Moid)
+
+	capacity := output.Section{Title: "Capacity"}
+	capacity.AddField("Hosts", fmt.Sprintf("%d (%d effective)", r.NumHosts, r.NumEffective))
+	capacity.AddField("CPU Cores", fmt.Sprintf("%d", r.CPUCores))
+	capacity.AddField("CPU", output.FormatUsage(r.CPU.Used, r.CPU.Total, output.FormatMHz))
+	capacity.AddField("Memory", output.FormatUsage(r.Memory.Used, r.Memory.Total, output.FormatBytes))
+
+	members := output.Section{Title: "Members"}
+	members.AddField("Hosts", memberList(r.Hosts))
+	members.AddField("Datastores", memberList(r.Datastores))
+	members.AddField("Networks", memberList(r.Networks))
+	members.AddField("VMs", fmt.Sprintf("%d", r.NumVMs))
+
+	return "Cluster: " + r.Name, []output.Section{general, capacity, members}
+}
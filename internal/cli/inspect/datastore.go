@@ -0,0 +1,66 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+func newDatastoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "datastore <datastore-name>",
+		Short: "Display datastore information",
+		Long: `Displays information about a datastore.
+
+Information displayed:
+  - General: Type, URL, health, accessibility, maintenance mode
+  - Capacity: Capacity, used, free and uncommitted space
+  - Members: Hosts mounting the datastore and VMs stored on it
+
+Examples:
+  vcli inspect datastore datastore1
+  vcli inspect datastore datastore1 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, args[0], func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error) {
+				ds, err := finder.Datastore(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return vsphere.BuildDatastoreReport(ctx, c, ds)
+			}, datastoreSections)
+		},
+	}
+
+	return cmd
+}
+
+// datastoreSections renders a datastore report as sectioned text
+func datastoreSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.DatastoreReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Type", r.Type)
+	general.AddField("URL", r.URL)
+	general.AddField("Health", r.OverallStatus)
+	general.AddField("Accessible", output.FormatBool(r.Accessible))
+	general.AddField("Maintenance Mode", output.OrNone(r.MaintenanceMode))
+	general.AddField("MOID", r.Moid)
+
+	capacity := output.Section{Title: "Capacity"}
+	capacity.AddField("Used", output.FormatUsage(r.UsedBytes, r.CapacityBytes, output.FormatBytes))
+	capacity.AddField("Free", output.FormatBytes(r.FreeBytes))
+	capacity.AddField("Uncommitted", output.FormatBytes(r.UncommittedBytes))
+
+	members := output.Section{Title: "Members"}
+	members.AddField("Hosts", memberList(r.Hosts))
+	members.AddField("VMs", fmt.Sprintf("%d", len(r.VMs)))
+
+	return "Datastore: " + r.Name, []output.Section{general, capacity, members}
+}
@@ -0,0 +1,142 @@
+package inspect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffSnapshot string
+	diffColor    string
+)
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <vm-a> [vm-b]",
+		Short: "Compare two VMs or a VM against one of its snapshots",
+		Long: `Compares the configuration of two virtual machines, or of a virtual machine
+against the configuration captured by one of its snapshots.
+
+Compared settings:
+  - Hardware: CPUs, cores per socket, memory, version, firmware, guest ID, hot-add
+  - Disks: capacity, controller, unit, provisioning, disk mode, sharing
+  - Networks: adapter type, network, MAC address, start connected
+  - Devices: all other virtual devices by label
+  - Advanced settings: extraConfig keys
+
+Table output is a unified diff (colored on terminals). JSON and YAML output
+is a JSON Patch that turns the first configuration into the second, with
+the previous value of each changed key in "oldValue".
+
+Examples:
+  vcli inspect diff source-vm my-clone
+  vcli inspect diff my-vm --against-snapshot before-upgrade
+  vcli inspect diff source-vm my-clone -o json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if diffSnapshot == "" && len(args) != 2 {
+				return fmt.Errorf("specify two VMs, or one VM and --against-snapshot")
+			}
+			if diffSnapshot != "" && len(args) != 1 {
+				return fmt.Errorf("--against-snapshot compares a single VM against its snapshot")
+			}
+
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vmA, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			var fromName, toName string
+			var from, to *types.VirtualMachineConfigInfo
+			if diffSnapshot != "" {
+				fromName = fmt.Sprintf("%s@%s", args[0], diffSnapshot)
+				toName = args[0]
+				if from, err = vsphere.SnapshotConfig(ctx, c.Client, vmA, diffSnapshot); err != nil {
+					return fmt.Errorf("failed to read snapshot %s: %w", diffSnapshot, err)
+				}
+				if to, err = vsphere.VMConfig(ctx, vmA); err != nil {
+					return err
+				}
+			} else {
+				fromName, toName = args[0], args[1]
+				vmB, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[1])
+				if err != nil {
+					return err
+				}
+				if from, err = vsphere.VMConfig(ctx, vmA); err != nil {
+					return err
+				}
+				if to, err = vsphere.VMConfig(ctx, vmB); err != nil {
+					return err
+				}
+			}
+
+			fromFields, err := vsphere.ConfigFields(ctx, c.Client, from)
+			if err != nil {
+				return err
+			}
+			toFields, err := vsphere.ConfigFields(ctx, c.Client, to)
+			if err != nil {
+				return err
+			}
+
+			changes := vsphere.DiffFields(fromFields, toFields)
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.PrintDiff(changes, diffColor, func(data interface{}) (string, string, []output.DiffLine) {
+				return fromName, toName, DiffLines(data.([]vsphere.Change))
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&diffSnapshot, "against-snapshot", "", "Compare the VM against this snapshot of itself")
+	cmd.Flags().StringVar(&diffColor, "color", output.ColorAuto, "Color the diff: auto, always or never")
+
+	return cmd
+}
+
+// DiffLines renders configuration changes as unified diff lines, with a hunk
+// per section
+func DiffLines(changes []vsphere.Change) []output.DiffLine {
+	var lines []output.DiffLine
+	section := ""
+
+	for _, ch := range changes {
+		s, key, _ := strings.Cut(strings.TrimPrefix(ch.Path, "/"), "/")
+		if s != section {
+			section = s
+			lines = append(lines, output.DiffLine{Op: '@', Text: section})
+		}
+		key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+
+		if ch.Old != nil {
+			lines = append(lines, output.DiffLine{Op: '-', Text: fmt.Sprintf("%s: %s", key, *ch.Old)})
+		}
+		if ch.New != nil {
+			lines = append(lines, output.DiffLine{Op: '+', Text: fmt.Sprintf("%s: %s", key, *ch.New)})
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,99 @@
+package inspect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newDisksCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disks <vm-name>",
+		Short: "Display virtual disk details and migration blockers",
+		Long: `Lists each virtual disk of a VM with details relevant to migration.
+
+Information displayed:
+  - Controller and unit number, including SCSI bus sharing
+  - Backing type: flat, sparse, sesparse, rdm-physical, rdm-virtual, vvol, pmem
+  - Provisioning: thin, thick-lazy, thick-eager
+  - Disk mode and sharing mode
+  - Changed Block Tracking (CBT) and encryption
+  - Snapshot chain depth, and the base disk of linked clones
+
+Blockers (RDMs, shared or independent disks, encryption, ...) prevent the disk
+from being migrated. Warnings (CBT disabled, snapshots present, ...) make a
+migration slower or riskier.
+
+Examples:
+  vcli inspect disks my-vm
+  vcli inspect disks my-vm -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			report, err := vsphere.BuildDiskReport(ctx, c.Client, vm)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"DISK", "CAPACITY", "CONTROLLER", "BACKING", "PROVISIONING", "MODE", "SHARING", "CBT", "ENCRYPTED", "CHAIN", "BLOCKERS", "WARNINGS"}
+			return formatter.Print(report, headers, diskRows)
+		},
+	}
+
+	return cmd
+}
+
+func diskRows(data interface{}) [][]string {
+	r := data.(*vsphere.DiskReport)
+
+	rows := [][]string{}
+	for _, d := range r.Disks {
+		controller := fmt.Sprintf("%s (%s) unit %d", d.Controller, d.ControllerType, d.UnitNumber)
+		if d.BusSharing != "" && d.BusSharing != "noSharing" {
+			controller += ", bus " + d.BusSharing
+		}
+		chain := fmt.Sprintf("%d", d.ChainDepth)
+		if d.LinkedCloneBase != "" {
+			chain += " + linked base"
+		}
+		rows = append(rows, []string{
+			d.Label,
+			output.FormatBytes(d.CapacityBytes),
+			controller,
+			d.Backing,
+			output.OrNone(d.Provisioning),
+			d.DiskMode,
+			output.OrNone(d.Sharing),
+			output.FormatBool(d.CBT),
+			output.FormatBool(d.Encrypted),
+			chain,
+			output.OrNone(strings.Join(d.Blockers, "; ")),
+			output.OrNone(strings.Join(d.Warnings, "; ")),
+		})
+	}
+	return rows
+}
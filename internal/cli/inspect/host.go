@@ -0,0 +1,75 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+func newHostCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "host <host-name>",
+		Short: "Display ESXi host information",
+		Long: `Displays information about an ESXi host.
+
+Information displayed:
+  - General: Connection state, maintenance mode, health, ESXi version
+  - Hardware: Vendor, model, CPU packages, cores and threads
+  - Usage: CPU and memory usage against capacity
+  - Members: VMs, datastores and networks on the host
+
+Examples:
+  vcli inspect host esxi-01.example.com
+  vcli inspect host esxi-01.example.com -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, args[0], func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error) {
+				host, err := finder.HostSystem(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return vsphere.BuildHostReport(ctx, c, host)
+			}, hostSections)
+		},
+	}
+
+	return cmd
+}
+
+// hostSections renders a host report as sectioned text
+func hostSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.HostReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Connection State", r.ConnectionState)
+	general.AddField("Power State", r.PowerState)
+	general.AddField("Maintenance Mode", output.FormatBool(r.MaintenanceMode))
+	general.AddField("Health", r.OverallStatus)
+	general.AddField("Version", fmt.Sprintf("ESXi %s (build %s)", r.Version, r.Build))
+	general.AddField("Cluster", output.OrNone(r.Cluster))
+	general.AddField("Uptime", r.Uptime)
+	general.AddField("MOID", r.Moid)
+
+	hardware := output.Section{Title: "Hardware"}
+	hardware.AddField("Vendor", r.Vendor)
+	hardware.AddField("Model", r.Model)
+	hardware.AddField("CPU Model", r.CPUModel)
+	hardware.AddField("CPUs", fmt.Sprintf("%d sockets, %d cores, %d threads", r.CPUSockets, r.CPUCores, r.CPUThreads))
+
+	usage := output.Section{Title: "Usage"}
+	usage.AddField("CPU", output.FormatUsage(r.CPU.Used, r.CPU.Total, output.FormatMHz))
+	usage.AddField("Memory", output.FormatUsage(r.Memory.Used, r.Memory.Total, output.FormatBytes))
+
+	members := output.Section{Title: "Members"}
+	members.AddField("VMs", fmt.Sprintf("%d", len(r.VMs)))
+	members.AddField("Datastores", memberList(r.Datastores))
+	members.AddField("Networks", memberList(r.Networks))
+
+	return "Host: " + r.Name, []output.Section{general, hardware, usage, members}
+}
@@ -9,13 +9,27 @@ func NewInspectCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "inspect",
 		Short: "Inspect virtual machines and resources",
-		Long: `Inspect virtual machines and display detailed information.
+		Long: `Inspect virtual machines and infrastructure and display detailed information.
 
 Available subcommands:
-  vm  - Display comprehensive VM information`,
+  vm             - Display comprehensive VM information
+  host           - Display ESXi host information
+  cluster        - Display cluster information
+  datastore      - Display datastore information
+  network        - Display network information
+  resource-pool  - Display resource pool information
+  disks          - Display virtual disk details and migration blockers
+  diff           - Compare two VMs or a VM against a snapshot`,
 	}
 
 	cmd.AddCommand(newVMCmd())
+	cmd.AddCommand(newHostCmd())
+	cmd.AddCommand(newClusterCmd())
+	cmd.AddCommand(newDatastoreCmd())
+	cmd.AddCommand(newNetworkCmd())
+	cmd.AddCommand(newResourcePoolCmd())
+	cmd.AddCommand(newDisksCmd())
+	cmd.AddCommand(newDiffCmd())
 
 	return cmd
 }
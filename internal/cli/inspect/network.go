@@ -0,0 +1,63 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+func newNetworkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network <network-name>",
+		Short: "Display network information",
+		Long: `Displays information about a standard or distributed port group.
+
+Information displayed:
+  - General: Type, health, accessibility, switch, VLAN
+  - Members: Hosts the network is available on and connected VMs
+
+Examples:
+  vcli inspect network "VM Network"
+  vcli inspect network dvpg-ci -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, args[0], func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error) {
+				network, err := finder.Network(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return vsphere.BuildNetworkReport(ctx, c, network.Reference())
+			}, networkSections)
+		},
+	}
+
+	return cmd
+}
+
+// networkSections renders a network report as sectioned text
+func networkSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.NetworkReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Type", r.Type)
+	general.AddField("Health", r.OverallStatus)
+	general.AddField("Accessible", output.FormatBool(r.Accessible))
+	if r.Switch != "" {
+		general.AddField("Switch", r.Switch)
+		general.AddField("VLAN", output.OrNone(r.VLAN))
+		general.AddField("Ports", fmt.Sprintf("%d", r.NumPorts))
+	}
+	general.AddField("MOID", r.Moid)
+
+	members := output.Section{Title: "Members"}
+	members.AddField("Hosts", memberList(r.Hosts))
+	members.AddField("VMs", memberList(r.VMs))
+
+	return "Network: " + r.Name, []output.Section{general, members}
+}
@@ -0,0 +1,57 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+// reportBuilder looks up an inventory object by name or path and builds its report
+type reportBuilder func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error)
+
+// runReport connects to vSphere, builds the report for name and prints it
+func runReport(cmd *cobra.Command, name string, build reportBuilder, sections func(interface{}) (string, []output.Section)) error {
+	ctx := cmd.Context()
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+
+	c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+	if err != nil {
+		return err
+	}
+
+	report, err := build(ctx, c.Client, vsphere.NewFinder(c.Client, global.DefaultDatacenterMoid), name)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+	return formatter.PrintReport(report, sections)
+}
+
+// memberList renders a list of member names, summarizing long lists as a count
+func memberList(names []string) string {
+	const maxListed = 10
+
+	switch {
+	case len(names) == 0:
+		return "<none>"
+	case len(names) > maxListed:
+		return fmt.Sprintf("%s, ... (%d total)", strings.Join(names[:maxListed], ", "), len(names))
+	default:
+		return strings.Join(names, ", ")
+	}
+}
@@ -0,0 +1,81 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+func newResourcePoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resource-pool <pool-name>",
+		Short: "Display resource pool information",
+		Long: `Displays information about a resource pool.
+
+Information displayed:
+  - General: Owner cluster or host, health
+  - CPU and Memory: Reservation, limit, shares and current usage
+  - Members: Child pools and VMs
+
+Examples:
+  vcli inspect resource-pool ci
+  vcli inspect resource-pool /dc/host/cluster/Resources/ci -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReport(cmd, args[0], func(ctx context.Context, c *vim25.Client, finder *find.Finder, name string) (interface{}, error) {
+				pool, err := finder.ResourcePool(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return vsphere.BuildResourcePoolReport(ctx, c, pool)
+			}, resourcePoolSections)
+		},
+	}
+
+	return cmd
+}
+
+// resourcePoolSections renders a resource pool report as sectioned text
+func resourcePoolSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.ResourcePoolReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Owner", r.Owner)
+	general.AddField("Health", r.OverallStatus)
+	general.AddField("MOID", r.Moid)
+
+	cpu := output.Section{Title: "CPU"}
+	addAllocation(&cpu, r.CPU, output.FormatMHz)
+
+	memory := output.Section{Title: "Memory"}
+	addAllocation(&memory, r.Memory, output.FormatBytes)
+
+	members := output.Section{Title: "Members"}
+	members.AddField("Child Pools", memberList(r.Children))
+	members.AddField("VMs", fmt.Sprintf("%d", len(r.VMs)))
+
+	return "Resource Pool: " + r.Name, []output.Section{general, cpu, memory, members}
+}
+
+func addAllocation(s *output.Section, a vsphere.Allocation, format func(int64) string) {
+	reservation := format(a.Reservation)
+	if a.Expandable {
+		reservation += " (expandable)"
+	}
+	limit := "unlimited"
+	if a.Limit >= 0 {
+		limit = format(a.Limit)
+	}
+
+	s.AddField("Reservation", reservation)
+	s.AddField("Limit", limit)
+	s.AddField("Shares", a.Shares)
+	s.AddField("Reservation Used", format(a.ReservationUse))
+	s.AddField("Usage", format(a.OverallUsage))
+}
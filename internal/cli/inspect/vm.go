@@ -1,7 +1,15 @@
 package inspect
 
 import (
-	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
 
 	"github.com/spf13/cobra"
 )
@@ -26,9 +34,110 @@ Examples:
   vcli inspect vm my-vm -o json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return errors.New("not implemented")
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			report, err := vsphere.BuildVMReport(ctx, c.Client, vm)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.PrintReport(report, vmSections)
 		},
 	}
 
 	return cmd
 }
+
+// vmSections renders a VM report as the sectioned text shown in table mode
+func vmSections(data interface{}) (string, []output.Section) {
+	r := data.(*vsphere.VMReport)
+
+	general := output.Section{Title: "General Information"}
+	general.AddField("Power State", r.PowerState)
+	general.AddField("Guest OS", r.General.GuestOS)
+	general.AddField("VMware Tools", toolsSummary(r.General))
+	general.AddField("Hostname", output.OrNone(r.General.Hostname))
+	general.AddField("UUID", r.General.UUID)
+	general.AddField("MOID", r.Moid)
+	if r.General.Template {
+		general.AddField("Template", "yes")
+	}
+
+	hardware := output.Section{Title: "Hardware Configuration"}
+	hardware.AddField("CPUs", fmt.Sprintf("%d (%d per socket)", r.Hardware.CPUs, r.Hardware.CoresPerSocket))
+	hardware.AddField("Memory", fmt.Sprintf("%d MB", r.Hardware.MemoryMB))
+	hardware.AddField("NICs", fmt.Sprintf("%d", r.Hardware.NICs))
+	hardware.AddField("Disks", fmt.Sprintf("%d", r.Hardware.Disks))
+	hardware.AddField("Version", r.Hardware.HardwareVersion)
+	hardware.AddField("Firmware", r.Hardware.Firmware)
+
+	compute := output.Section{Title: "Compute"}
+	compute.AddField("Host", output.OrNone(r.Compute.Host))
+	compute.AddField("Cluster", output.OrNone(r.Compute.Cluster))
+	compute.AddField("Resource Pool", output.OrNone(r.Compute.ResourcePool))
+
+	storage := output.Section{Title: "Storage"}
+	storage.AddField("Datastore", output.OrNone(strings.Join(r.Storage.Datastores, ", ")))
+	storage.AddField("Provisioned", output.FormatBytes(r.Storage.ProvisionedBytes))
+	storage.AddField("Used", output.FormatBytes(r.Storage.UsedBytes))
+	for i, d := range r.Storage.Disks {
+		provisioning := "thick provisioned"
+		if d.Thin {
+			provisioning = "thin provisioned"
+		}
+		storage.AddField(fmt.Sprintf("Disk %d", i+1), fmt.Sprintf("%s (%s, %s used)",
+			output.FormatBytes(d.CapacityBytes), provisioning, output.FormatBytes(d.UsedBytes)))
+		storage.AddNested("File", d.File)
+	}
+
+	network := output.Section{Title: "Network"}
+	if len(r.Network) == 0 {
+		network.AddField("NICs", "<none>")
+	}
+	for i, n := range r.Network {
+		network.AddField(fmt.Sprintf("NIC %d", i+1), fmt.Sprintf("%s (%s)", output.OrNone(n.Network), n.AdapterType))
+		network.AddNested("MAC", n.MAC)
+		network.AddNested("Connected", output.FormatBool(n.Connected))
+		network.AddNested("IP", output.OrNone(strings.Join(n.IPs, ", ")))
+	}
+
+	snapshots := output.Section{Title: "Snapshots"}
+	snapshots.AddField("Count", fmt.Sprintf("%d", r.Snapshots.Count))
+	snapshots.AddField("Current", output.OrNone(r.Snapshots.Current))
+	snapshots.AddField("Total Size", output.FormatBytes(r.Snapshots.TotalBytes))
+
+	metadata := output.Section{Title: "Metadata"}
+	created := "<unknown>"
+	if r.Metadata.CreatedAt != nil {
+		created = r.Metadata.CreatedAt.Local().Format(time.RFC3339)
+	}
+	metadata.AddField("Created", created)
+	metadata.AddField("Last Modified", r.Metadata.ModifiedAt.Local().Format(time.RFC3339))
+	metadata.AddField("Annotation", output.OrNone(strings.ReplaceAll(r.Metadata.Annotation, "\n", " ")))
+
+	return "VM: " + r.Name, []output.Section{general, hardware, compute, storage, network, snapshots, metadata}
+}
+
+func toolsSummary(g vsphere.VMGeneral) string {
+	status := output.OrNone(g.ToolsStatus)
+	if g.ToolsVersion != "" {
+		status = fmt.Sprintf("%s (version %s)", status, g.ToolsVersion)
+	}
+	return status
+}
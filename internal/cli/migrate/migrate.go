@@ -0,0 +1,21 @@
+package migrate
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewMigrateCmd creates the migrate command
+func NewMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Plan migrations of virtual machines",
+		Long: `Check whether virtual machines are ready to be migrated off vSphere.
+
+Available subcommands:
+  preflight  - Run migration readiness checks against VMs`,
+	}
+
+	cmd.AddCommand(newPreflightCmd())
+
+	return cmd
+}
@@ -0,0 +1,226 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/vim25/mo"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	preflightSelector string
+	preflightSkip     []string
+)
+
+// preflightReport is the full pre-flight output, consumed by migration planning
+type preflightReport struct {
+	CheckedAt time.Time                   `json:"checkedAt" yaml:"checkedAt"`
+	Rules     []preflightRuleInfo         `json:"rules" yaml:"rules"`
+	Summary   map[vsphere.CheckStatus]int `json:"summary" yaml:"summary"`
+	VMs       []*vsphere.PreflightResult  `json:"vms" yaml:"vms"`
+}
+
+type preflightRuleInfo struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func newPreflightCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preflight [vm-name...]",
+		Short: "Run migration readiness checks against VMs",
+		Long: `Runs the migration rule set against VMs selected by name or by a --selector
+glob and prints a pass/warn/fail matrix. Templates matched by --selector are skipped.
+
+Rules:
+  disk-types         - Disks use a supported backing (fail on pmem/unknown, warn on sparse/vVol)
+  rdm                - No raw device mappings
+  shared-disks       - No multi-writer or SCSI bus-shared disks
+  independent-disks  - No independent disks
+  cbt                - Changed Block Tracking is enabled (warn)
+  snapshots          - No snapshots present (warn)
+  tools              - VMware Tools is running on powered-on VMs (warn)
+  guest-os           - Guest OS is supported
+  passthrough        - No PCI passthrough or SR-IOV devices (warn on USB)
+  encryption         - VM and disks are not encrypted
+
+Table output shows the matrix followed by the findings. JSON and YAML output
+include every check with its message, for migration planning pipelines.
+The command exits non-zero if any VM fails.
+
+Examples:
+  vcli migrate preflight my-vm
+  vcli migrate preflight --selector 'app-*' -o json
+  vcli migrate preflight --selector '*' --skip cbt,snapshots`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if len(args) == 0 && preflightSelector == "" {
+				return fmt.Errorf("specify VM names or --selector")
+			}
+			if preflightSelector != "" {
+				if _, err := vsphere.MatchName(preflightSelector, ""); err != nil {
+					return fmt.Errorf("invalid --selector: %w", err)
+				}
+			}
+
+			rules, err := selectRules(preflightSkip)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			props := vsphere.PreflightProps()
+			var vms []mo.VirtualMachine
+			for _, name := range args {
+				vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, name)
+				if err != nil {
+					return err
+				}
+				p, err := vsphere.RetrieveVM(ctx, c.Client, vm.Reference(), props)
+				if err != nil {
+					return err
+				}
+				vms = append(vms, p)
+			}
+
+			if preflightSelector != "" {
+				all, err := vsphere.ListVMs(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid), props)
+				if err != nil {
+					return err
+				}
+				for _, vm := range all {
+					if vm.Config == nil || vm.Config.Template {
+						continue
+					}
+					if ok, _ := vsphere.MatchName(preflightSelector, vm.Name); ok {
+						vms = append(vms, vm)
+					}
+				}
+			}
+
+			report := preflightReport{
+				CheckedAt: time.Now().UTC(),
+				Summary:   map[vsphere.CheckStatus]int{vsphere.CheckPass: 0, vsphere.CheckWarn: 0, vsphere.CheckFail: 0},
+				VMs:       []*vsphere.PreflightResult{},
+			}
+			for _, r := range rules {
+				report.Rules = append(report.Rules, preflightRuleInfo{Name: r.Name, Description: r.Description})
+			}
+
+			seen := make(map[string]bool)
+			for _, vm := range vms {
+				if seen[vm.Reference().Value] {
+					continue
+				}
+				seen[vm.Reference().Value] = true
+
+				result, err := vsphere.RunPreflight(ctx, c.Client, vm, rules)
+				if err != nil {
+					return fmt.Errorf("%s: %w", vm.Name, err)
+				}
+				report.VMs = append(report.VMs, result)
+				report.Summary[result.Status]++
+			}
+
+			if len(report.VMs) == 0 {
+				return fmt.Errorf("no VMs matched selector %q", preflightSelector)
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"VM"}
+			for _, r := range rules {
+				headers = append(headers, strings.ToUpper(r.Name))
+			}
+			headers = append(headers, "RESULT")
+
+			if err := formatter.Print(report, headers, preflightRows); err != nil {
+				return err
+			}
+			if output.Format(cmd.Flag("output").Value.String()) == output.FormatTable {
+				printFindings(cmd, report)
+			}
+
+			if failed := report.Summary[vsphere.CheckFail]; failed > 0 {
+				return fmt.Errorf("%d of %d VMs failed pre-flight checks", failed, len(report.VMs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&preflightSelector, "selector", "", "Check all VMs whose name matches this glob")
+	cmd.Flags().StringSliceVar(&preflightSkip, "skip", nil, "Rules to skip (comma-separated)")
+
+	return cmd
+}
+
+// selectRules returns the default rule set without the skipped rules
+func selectRules(skip []string) ([]vsphere.PreflightRule, error) {
+	skipped := make(map[string]bool)
+	for _, name := range skip {
+		skipped[name] = true
+	}
+
+	var rules []vsphere.PreflightRule
+	for _, r := range vsphere.PreflightRules {
+		if skipped[r.Name] {
+			delete(skipped, r.Name)
+			continue
+		}
+		rules = append(rules, r)
+	}
+
+	for name := range skipped {
+		return nil, fmt.Errorf("unknown rule %q in --skip", name)
+	}
+	return rules, nil
+}
+
+func preflightRows(data interface{}) [][]string {
+	report := data.(preflightReport)
+
+	rows := [][]string{}
+	for _, vm := range report.VMs {
+		row := []string{vm.VM}
+		for _, check := range vm.Checks {
+			row = append(row, strings.ToUpper(string(check.Status)))
+		}
+		row = append(row, strings.ToUpper(string(vm.Status)))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// printFindings lists the message of every warning and failure below the matrix
+func printFindings(cmd *cobra.Command, report preflightReport) {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "\n%d passed, %d warned, %d failed\n",
+		report.Summary[vsphere.CheckPass], report.Summary[vsphere.CheckWarn], report.Summary[vsphere.CheckFail])
+
+	for _, vm := range report.VMs {
+		for _, check := range vm.Checks {
+			if check.Status == vsphere.CheckPass {
+				continue
+			}
+			fmt.Fprintf(out, "  %s %s [%s]: %s\n", strings.ToUpper(string(check.Status)), vm.VM, check.Rule, check.Message)
+		}
+	}
+}
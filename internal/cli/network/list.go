@@ -0,0 +1,95 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var listType string
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List port groups with their switch, VLAN and VM count",
+		Long: `Lists the port groups of the datacenter with their type, the switch they
+belong to, their VLAN and the number of hosts and VMs attached to them.
+Distributed uplink port groups are not listed.
+
+For standard port groups, the vSwitch and VLAN are read from each host's
+port group definition. When hosts disagree, all values are shown.
+
+VLAN values:
+  none          Untagged (standard VLAN 0)
+  trunk         All VLANs passed to the guest (standard VLAN 4095)
+  trunk 1-4094  Distributed trunk ranges
+  pvlan N       Distributed private VLAN
+
+Examples:
+  vcli network list
+  vcli network list --type distributed
+  vcli network list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			switch listType {
+			case "", vsphere.NetworkStandard, vsphere.NetworkDistributed, vsphere.NetworkOpaque:
+			default:
+				return fmt.Errorf("invalid --type %q (must be %s, %s or %s)", listType, vsphere.NetworkStandard, vsphere.NetworkDistributed, vsphere.NetworkOpaque)
+			}
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			networks, err := vsphere.ListNetworks(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid))
+			if err != nil {
+				return err
+			}
+
+			if listType != "" {
+				filtered := []vsphere.NetworkSummary{}
+				for _, n := range networks {
+					if n.Type == listType {
+						filtered = append(filtered, n)
+					}
+				}
+				networks = filtered
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(networks, []string{"NAME", "TYPE", "SWITCH", "VLAN", "HOSTS", "VMS", "ACCESSIBLE"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, n := range data.([]vsphere.NetworkSummary) {
+					rows = append(rows, []string{
+						n.Name,
+						n.Type,
+						output.OrNone(n.Switch),
+						output.OrNone(n.VLAN),
+						fmt.Sprintf("%d", n.NumHosts),
+						fmt.Sprintf("%d", n.NumVMs),
+						output.FormatBool(n.Accessible),
+					})
+				}
+				return rows
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&listType, "type", "", "Only list port groups of this type: standard, distributed or opaque")
+
+	return cmd
+}
@@ -0,0 +1,24 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewNetworkCmd creates the network command
+func NewNetworkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "List networks and the VMs attached to them",
+		Long: `List standard, distributed and opaque port groups and the VMs attached
+to them, e.g. to pick a target network for clones.
+
+Available subcommands:
+  list  - List port groups with their switch, VLAN and VM count
+  vms   - List the VMs and NICs attached to a port group`,
+	}
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newVMsCmd())
+
+	return cmd
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+func newVMsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "vms <portgroup>",
+		Short: "List the VMs and NICs attached to a port group",
+		Long: `Lists every NIC attached to a port group with its VM, MAC address,
+connection state and guest IP addresses (when VMware Tools reports them).
+A VM with several NICs on the port group is listed once per NIC.
+
+Examples:
+  vcli network vms "VM Network"
+  vcli network vms dvpg-ci -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			network, err := vsphere.NewFinder(c.Client, global.DefaultDatacenterMoid).Network(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("network %q: %w", args[0], err)
+			}
+
+			entries, err := vsphere.NetworkVMs(ctx, c.Client, network)
+			if err != nil {
+				return err
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(entries, []string{"VM", "NIC", "MAC", "CONNECTED", "POWER", "IP"}, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, e := range data.([]vsphere.NetworkVM) {
+					rows = append(rows, []string{
+						e.VM,
+						e.NIC,
+						e.MAC,
+						output.FormatBool(e.Connected),
+						e.PowerState,
+						output.OrNone(e.IPAddress),
+					})
+				}
+				return rows
+			})
+		},
+	}
+}
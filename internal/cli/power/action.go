@@ -0,0 +1,212 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	"github.com/asegev/vsphere-cli/pkg/workerpool"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	powerWait     bool
+	powerForce    bool
+	powerTimeout  time.Duration
+	powerParallel int
+)
+
+// powerFunc changes the power state of a VM and reports whether it had to
+// fall back to a hard operation
+type powerFunc func(ctx context.Context, vm *object.VirtualMachine) (bool, error)
+
+// powerResult is the per-VM outcome of a power action
+type powerResult struct {
+	Name   string `json:"name" yaml:"name"`
+	Action string `json:"action" yaml:"action"`
+	State  string `json:"state" yaml:"state"`
+	Result string `json:"result" yaml:"result"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func newOnCmd() *cobra.Command {
+	cmd := newActionCmd("on", "Power on VMs", `Powers on one or more VMs. VMs that are already on are left alone.
+With --wait, blocks until VMware Tools is running in each guest.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			if err := vsphere.PowerOn(ctx, vm); err != nil {
+				return false, err
+			}
+			if powerWait {
+				return false, vsphere.WaitForTools(ctx, vm, true, powerTimeout)
+			}
+			return false, nil
+		})
+	addWaitFlags(cmd)
+	return cmd
+}
+
+func newOffCmd() *cobra.Command {
+	return newActionCmd("off", "Hard power off VMs", `Powers off one or more VMs without shutting down the guest.
+VMs that are already off are left alone. Use shutdown for a clean shutdown.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			return false, vsphere.PowerOff(ctx, vm)
+		})
+}
+
+func newResetCmd() *cobra.Command {
+	cmd := newActionCmd("reset", "Hard reset VMs", `Resets one or more powered-on VMs without rebooting the guest cleanly.
+With --wait, blocks until VMware Tools is running again in each guest.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			if err := vsphere.Reset(ctx, vm); err != nil {
+				return false, err
+			}
+			if powerWait {
+				return false, vsphere.WaitForGuestRestart(ctx, vm, powerTimeout)
+			}
+			return false, nil
+		})
+	addWaitFlags(cmd)
+	return cmd
+}
+
+func newSuspendCmd() *cobra.Command {
+	return newActionCmd("suspend", "Suspend VMs", `Suspends one or more VMs. VMs that are already suspended are left alone.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			return false, vsphere.Suspend(ctx, vm)
+		})
+}
+
+func newShutdownCmd() *cobra.Command {
+	cmd := newActionCmd("shutdown", "Shut down guests through VMware Tools", `Asks the guest OS of one or more VMs to shut down through VMware Tools.
+
+With --wait, blocks until each VM is powered off, for up to --timeout. With
+--force, VMs that did not power off within --timeout (or whose Tools are not
+running) are powered off hard.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			return vsphere.Shutdown(ctx, vm, powerTimeout, powerForce, powerWait)
+		})
+	addWaitFlags(cmd)
+	cmd.Flags().BoolVar(&powerForce, "force", false, "Power off hard if the guest does not shut down in time")
+	return cmd
+}
+
+func newRebootCmd() *cobra.Command {
+	cmd := newActionCmd("reboot", "Reboot guests through VMware Tools", `Asks the guest OS of one or more VMs to reboot through VMware Tools.
+
+With --wait, blocks until VMware Tools is running again in each guest, for up
+to --timeout. With --force, VMs that did not restart within --timeout (or
+whose Tools are not running) are reset.`,
+		func(ctx context.Context, vm *object.VirtualMachine) (bool, error) {
+			return vsphere.Reboot(ctx, vm, powerTimeout, powerForce, powerWait)
+		})
+	addWaitFlags(cmd)
+	cmd.Flags().BoolVar(&powerForce, "force", false, "Reset the VM if the guest does not restart in time")
+	return cmd
+}
+
+// newActionCmd builds a power subcommand that applies fn to every VM argument
+func newActionCmd(action, short, long string, fn powerFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   action + " <vm...>",
+		Short: short,
+		Long: long + `
+
+Examples:
+  vcli power ` + action + ` my-vm
+  vcli power ` + action + ` vm-1 vm-2 vm-3 --parallel 3`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAction(cmd, args, action, fn)
+		},
+	}
+
+	cmd.Flags().IntVar(&powerParallel, "parallel", 4, "Maximum number of VMs to operate on concurrently")
+
+	return cmd
+}
+
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&powerWait, "wait", false, "Wait until the target state is reached")
+	cmd.Flags().DurationVar(&powerTimeout, "timeout", 5*time.Minute, "How long to wait for the guest")
+}
+
+// runAction applies fn to the named VMs in parallel and prints the outcome
+func runAction(cmd *cobra.Command, names []string, action string, fn powerFunc) error {
+	ctx := cmd.Context()
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		return err
+	}
+
+	c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+	if err != nil {
+		return err
+	}
+
+	vms := make([]*object.VirtualMachine, 0, len(names))
+	for _, name := range names {
+		vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, name)
+		if err != nil {
+			return err
+		}
+		vms = append(vms, vm)
+	}
+
+	results := make([]powerResult, len(vms))
+	runs := workerpool.Run(ctx, len(vms), powerParallel, func(ctx context.Context, i int) error {
+		forced, err := fn(ctx, vms[i])
+		if err != nil {
+			return err
+		}
+		if forced {
+			results[i].Result = "forced"
+		}
+		return nil
+	})
+
+	failed := 0
+	for i, run := range runs {
+		results[i].Name = names[i]
+		results[i].Action = action
+		switch {
+		case run.Err != nil:
+			failed++
+			results[i].Result = "failed"
+			results[i].Error = run.Err.Error()
+		case results[i].Result == "":
+			results[i].Result = "ok"
+		}
+
+		state, err := vms[i].PowerState(ctx)
+		if err != nil {
+			results[i].State = "unknown"
+			continue
+		}
+		results[i].State = string(state)
+	}
+
+	formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+	headers := []string{"VM", "ACTION", "STATE", "RESULT", "ERROR"}
+	if err := formatter.Print(results, headers, func(data interface{}) [][]string {
+		rows := [][]string{}
+		for _, r := range data.([]powerResult) {
+			rows = append(rows, []string{r.Name, r.Action, r.State, r.Result, r.Error})
+		}
+		return rows
+	}); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("power %s failed on %d of %d VMs", action, failed, len(vms))
+	}
+	return nil
+}
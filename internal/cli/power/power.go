@@ -0,0 +1,33 @@
+package power
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewPowerCmd creates the power command
+func NewPowerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "power",
+		Short: "Manage the power state of virtual machines",
+		Long: `Change or show the power state of one or more virtual machines.
+
+Available subcommands:
+  on        - Power on VMs
+  off       - Hard power off VMs
+  reset     - Hard reset VMs
+  suspend   - Suspend VMs
+  shutdown  - Shut down guests through VMware Tools
+  reboot    - Reboot guests through VMware Tools
+  status    - Show the power state of VMs`,
+	}
+
+	cmd.AddCommand(newOnCmd())
+	cmd.AddCommand(newOffCmd())
+	cmd.AddCommand(newResetCmd())
+	cmd.AddCommand(newSuspendCmd())
+	cmd.AddCommand(newShutdownCmd())
+	cmd.AddCommand(newRebootCmd())
+	cmd.AddCommand(newStatusCmd())
+
+	return cmd
+}
@@ -0,0 +1,86 @@
+package power
+
+import (
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+// powerStatus is a row of power status
+type powerStatus struct {
+	Name       string     `json:"name" yaml:"name"`
+	PowerState string     `json:"powerState" yaml:"powerState"`
+	Tools      string     `json:"tools" yaml:"tools"`
+	BootTime   *time.Time `json:"bootTime,omitempty" yaml:"bootTime,omitempty"`
+}
+
+func newStatusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <vm...>",
+		Short: "Show the power state of VMs",
+		Long: `Shows the power state, VMware Tools status and boot time of one or more VMs.
+
+Examples:
+  vcli power status my-vm
+  vcli power status vm-1 vm-2 -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			statuses := make([]powerStatus, 0, len(args))
+			for _, name := range args {
+				vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, name)
+				if err != nil {
+					return err
+				}
+
+				props, err := vsphere.RetrieveVM(ctx, c.Client, vm.Reference(), []string{"name", "runtime.powerState", "runtime.bootTime", "guest.toolsRunningStatus"})
+				if err != nil {
+					return err
+				}
+
+				s := powerStatus{
+					Name:       props.Name,
+					PowerState: string(props.Runtime.PowerState),
+					BootTime:   props.Runtime.BootTime,
+				}
+				if props.Guest != nil {
+					s.Tools = props.Guest.ToolsRunningStatus
+				}
+				statuses = append(statuses, s)
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"VM", "POWER STATE", "TOOLS", "BOOT TIME"}
+			return formatter.Print(statuses, headers, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, s := range data.([]powerStatus) {
+					boot := ""
+					if s.BootTime != nil {
+						boot = s.BootTime.Local().Format(time.RFC3339)
+					}
+					rows = append(rows, []string{s.Name, s.PowerState, s.Tools, boot})
+				}
+				return rows
+			})
+		},
+	}
+
+	return cmd
+}
@@ -3,10 +3,20 @@ package cli
 import (
 	"fmt"
 
+	"github.com/asegev/vsphere-cli/internal/cli/cbt"
 	"github.com/asegev/vsphere-cli/internal/cli/clone"
+	"github.com/asegev/vsphere-cli/internal/cli/cluster"
 	"github.com/asegev/vsphere-cli/internal/cli/credentials"
+	"github.com/asegev/vsphere-cli/internal/cli/datastore"
+	"github.com/asegev/vsphere-cli/internal/cli/guest"
+	"github.com/asegev/vsphere-cli/internal/cli/host"
 	"github.com/asegev/vsphere-cli/internal/cli/inspect"
+	"github.com/asegev/vsphere-cli/internal/cli/migrate"
+	"github.com/asegev/vsphere-cli/internal/cli/network"
+	"github.com/asegev/vsphere-cli/internal/cli/power"
 	"github.com/asegev/vsphere-cli/internal/cli/snapshot"
+	"github.com/asegev/vsphere-cli/internal/cli/template"
+	"github.com/asegev/vsphere-cli/internal/cli/vm"
 	"github.com/asegev/vsphere-cli/pkg/config"
 	"github.com/asegev/vsphere-cli/pkg/output"
 	"github.com/spf13/cobra"
@@ -14,7 +24,10 @@ import (
 
 var longDescription = `vcli is a command-line tool for managing VMware vSphere environments.
 
-It provides commands for snapshot management, VM cloning, VM inspection,
+It provides commands for VM inventory, snapshot management, VM cloning,
+template management, VM inspection, power management, guest operations,
+migration pre-flight checks, Changed Block Tracking, datastore browsing and
+file transfer, network, host and cluster inventory, host maintenance mode,
 and credential validation.
 
 Authentication is configured via environment variables:
@@ -48,6 +61,13 @@ var rootCmd = &cobra.Command{
 			return nil
 		}
 
+		// Set output format, before anything else can fail
+		format, err := output.ParseFormat(flagOutput)
+		if err != nil {
+			return err
+		}
+		globalFormat = format
+
 		// Load config from environment
 		cfg, err := config.LoadFromEnv()
 		if err != nil {
@@ -77,14 +97,6 @@ var rootCmd = &cobra.Command{
 
 		globalConfig = cfg
 
-		// Set output format
-		switch flagOutput {
-		case "table", "json", "yaml":
-			globalFormat = output.Format(flagOutput)
-		default:
-			return fmt.Errorf("invalid output format: %s (must be table, json, or yaml)", flagOutput)
-		}
-
 		return nil
 	},
 }
@@ -100,7 +112,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&flagUsername, "username", "", "Username (overrides VCLI_USERNAME)")
 	rootCmd.PersistentFlags().StringVar(&flagPassword, "password", "", "Password (overrides VCLI_PASSWORD)")
 	rootCmd.PersistentFlags().BoolVar(&flagInsecure, "insecure", false, "Skip TLS verification (overrides VCLI_INSECURE)")
-	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "table", "Output format (table, json, yaml)")
+	rootCmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", "table", "Output format (table, json, yaml, jsonpath=<template>, go-template=<template>)")
 	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Verbose output")
 
 	// Add command groups
@@ -108,6 +120,16 @@ func init() {
 	rootCmd.AddCommand(snapshot.NewSnapshotCmd())
 	rootCmd.AddCommand(clone.NewCloneCmd())
 	rootCmd.AddCommand(inspect.NewInspectCmd())
+	rootCmd.AddCommand(template.NewTemplateCmd())
+	rootCmd.AddCommand(migrate.NewMigrateCmd())
+	rootCmd.AddCommand(cbt.NewCBTCmd())
+	rootCmd.AddCommand(guest.NewGuestCmd())
+	rootCmd.AddCommand(power.NewPowerCmd())
+	rootCmd.AddCommand(vm.NewVMCmd())
+	rootCmd.AddCommand(datastore.NewDatastoreCmd())
+	rootCmd.AddCommand(network.NewNetworkCmd())
+	rootCmd.AddCommand(host.NewHostCmd())
+	rootCmd.AddCommand(cluster.NewClusterCmd())
 }
 
 // Config returns the global config
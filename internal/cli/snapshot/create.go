@@ -2,8 +2,12 @@ package snapshot
 
 import (
 	"fmt"
+	"os"
+	"strings"
+
 	"github.com/asegev/vsphere-cli/internal/global"
 	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
 	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
 
 	"github.com/spf13/cobra"
@@ -14,7 +18,14 @@ var (
 	createName        string
 	createDescription string
 	createMemory      bool
-	createQuiesce     bool
+	createQuiesce     string
+)
+
+// --quiesce values
+const (
+	quiesceOff  = "false"
+	quiesceOn   = "true"
+	quiesceAuto = "auto"
 )
 
 func newCreateCmd() *cobra.Command {
@@ -25,10 +36,21 @@ func newCreateCmd() *cobra.Command {
 
 The snapshot name is auto-generated if not provided (snapshot-YYYY-MM-DD-HHMMSS).
 
+--quiesce checks up front that the VM is powered on, VMware Tools is running,
+the guest OS supports quiescing and no disk prevents it, and fails if not.
+--quiesce=auto falls back to a crash-consistent snapshot with a warning
+instead, including when the guest fails to quiesce during the snapshot.
+
+The output reports the consistency level achieved: powered-off, memory,
+application (Windows VSS), filesystem (Linux freeze) or crash.
+
 Examples:
   vcli snapshot create my-vm
   vcli snapshot create my-vm --name "before-upgrade"
-  vcli snapshot create my-vm --memory --description "With memory state"`,
+  vcli snapshot create my-vm --memory --description "With memory state"
+  vcli snapshot create my-vm --quiesce
+  vcli snapshot create my-vm --quiesce=auto`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -44,39 +66,79 @@ Examples:
 				return err
 			}
 
-			finder, err := vmware.NewDatacenterFinder(ctx, c.Client, global.DefaultDatacenterMoid)
+			if len(args) > 0 {
+				vmName = args[0]
+			}
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, vmName)
 			if err != nil {
 				return err
 			}
 
-			vm, err := finder.FindVMByName(ctx, vmName)
+			switch createQuiesce {
+			case quiesceOff, quiesceOn, quiesceAuto:
+			default:
+				return fmt.Errorf("invalid --quiesce value %q (must be true, false or auto)", createQuiesce)
+			}
+
+			check, err := vsphere.CheckQuiesce(ctx, c.Client, vm)
 			if err != nil {
 				return err
 			}
 
+			quiesce := false
+			consistency := vsphere.ConsistencyCrash
+			switch {
+			case !check.PoweredOn:
+				consistency = vsphere.ConsistencyPoweredOff
+			case createMemory:
+				if createQuiesce != quiesceOff {
+					fmt.Fprintln(os.Stderr, "Warning: --quiesce is ignored for snapshots that include memory")
+				}
+				consistency = vsphere.ConsistencyMemory
+			case createQuiesce == quiesceOff:
+			case check.Supported:
+				quiesce = true
+				consistency = check.Level
+			case createQuiesce == quiesceAuto:
+				fmt.Fprintf(os.Stderr, "Warning: cannot quiesce %s (%s); taking a crash-consistent snapshot\n",
+					vmName, strings.Join(check.Reasons, "; "))
+			default:
+				return fmt.Errorf("cannot take a quiesced snapshot of %s: %s (use --quiesce=auto to fall back to crash-consistent)",
+					vmName, strings.Join(check.Reasons, "; "))
+			}
+
 			req := vmware.CreateSnapshotRequest{
 				VmMoid:       vm.Reference().Value,
 				SnapshotName: createName,
 				Description:  createDescription,
 				Memory:       createMemory,
-				Quiesce:      createQuiesce,
+				Quiesce:      quiesce,
 			}
 
-			if err := dcm.CreateSnapshot(ctx, req); err != nil {
+			err = dcm.CreateSnapshot(ctx, req)
+			if err != nil && quiesce && createQuiesce == quiesceAuto && vsphere.IsQuiesceFault(err) {
+				fmt.Fprintf(os.Stderr, "Warning: guest failed to quiesce (%v); retrying crash-consistent\n", err)
+				req.Quiesce = false
+				consistency = vsphere.ConsistencyCrash
+				err = dcm.CreateSnapshot(ctx, req)
+			}
+			if err != nil {
 				return err
 			}
 
-			fmt.Printf("Snapshot %s created\n", vm.Reference().Value)
+			fmt.Printf("Snapshot %s created (consistency: %s)\n", vm.Reference().Value, consistency)
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&vmName, "vmName", global.DefaultVmName, "Snapshot name (from defaults.go if omitted)")
+	cmd.Flags().StringVar(&vmName, "vmName", global.DefaultVmName, "VM to snapshot when no argument is given (from defaults.go if omitted)")
+	_ = cmd.Flags().MarkDeprecated("vmName", "pass the VM as an argument instead")
 	cmd.Flags().StringVar(&createName, "name", global.DefaultSnapshotName, "Snapshot name (from defaults.go if omitted)")
 	cmd.Flags().StringVar(&createDescription, "description", "", "Snapshot description")
 	cmd.Flags().BoolVar(&createMemory, "memory", false, "Include VM memory state")
-	cmd.Flags().BoolVar(&createQuiesce, "quiesce", false, "Quiesce filesystem (requires VMware Tools)")
+	cmd.Flags().StringVar(&createQuiesce, "quiesce", quiesceOff, "Quiesce the guest: true, false or auto (fall back to crash-consistent); use --quiesce=<value>")
+	cmd.Flags().Lookup("quiesce").NoOptDefVal = quiesceOn
 
 	return cmd
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/asegev/vsphere-cli/internal/global"
 	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
 	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
 
 	"github.com/spf13/cobra"
@@ -23,6 +24,7 @@ Consolidates disks after deletion.
 Examples:
   vcli snapshot delete my-vm snapshot-2024-01-01
   vcli snapshot delete my-vm snapshot-2024-01-01 --force`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -38,19 +40,14 @@ Examples:
 				return err
 			}
 
-			finder, err := vmware.NewDatacenterFinder(ctx, c.Client, global.DefaultDatacenterMoid)
-			if err != nil {
-				return err
-			}
-
-			vm, err := finder.FindVMByName(ctx, vmName)
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
 			if err != nil {
 				return err
 			}
 
 			req := vmware.RemoveSnapshotRequest{
 				VmMoid:       vm.Reference().Value,
-				SnapshotName: createName,
+				SnapshotName: args[1],
 				Consolidate:  false,
 			}
 
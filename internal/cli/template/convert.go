@@ -0,0 +1,127 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertResourcePool string
+	convertHost         string
+)
+
+func newConvertToTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert-to-template <vm>",
+		Short: "Mark a VM as a template",
+		Long: `Marks a powered-off VM as a template.
+
+Examples:
+  vcli template convert-to-template golden-ubuntu`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			state, err := vm.PowerState(ctx)
+			if err != nil {
+				return err
+			}
+			if state != types.VirtualMachinePowerStatePoweredOff {
+				return fmt.Errorf("VM %s must be powered off to become a template (currently %s)", args[0], state)
+			}
+
+			if err := vm.MarkAsTemplate(ctx); err != nil {
+				return err
+			}
+
+			fmt.Printf("VM %s converted to template\n", vm.Reference().Value)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newConvertToVMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert-to-vm <template>",
+		Short: "Turn a template back into a VM",
+		Long: `Converts a template back into a regular VM.
+
+The VM is placed in the root resource pool of the default cluster unless
+--resource-pool is given. --esxi-host is only needed when DRS is disabled.
+
+Examples:
+  vcli template convert-to-vm golden-ubuntu
+  vcli template convert-to-vm golden-ubuntu --resource-pool /dc/host/cluster/Resources/ci`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			tmpl, err := vsphere.ResolveTemplate(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			pool, err := vsphere.ResourcePool(ctx, c.Client, global.DefaultDatacenterMoid, global.DefaultClusterMoid, convertResourcePool)
+			if err != nil {
+				return err
+			}
+
+			var host *object.HostSystem
+			if convertHost != "" {
+				host, err = vsphere.NewFinder(c.Client, global.DefaultDatacenterMoid).HostSystem(ctx, convertHost)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := tmpl.MarkAsVirtualMachine(ctx, *pool, host); err != nil {
+				return err
+			}
+
+			fmt.Printf("Template %s converted to VM\n", tmpl.Reference().Value)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&convertResourcePool, "resource-pool", "", "Resource pool name or path (default: root pool of the default cluster)")
+	cmd.Flags().StringVar(&convertHost, "esxi-host", "", "ESXi host name or path to register the VM on")
+
+	return cmd
+}
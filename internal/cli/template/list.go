@@ -0,0 +1,80 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+// templateInfo is a row of template list
+type templateInfo struct {
+	Name     string `json:"name" yaml:"name"`
+	Moid     string `json:"moid" yaml:"moid"`
+	GuestOS  string `json:"guestOS" yaml:"guestOS"`
+	CPUs     int32  `json:"cpus" yaml:"cpus"`
+	MemoryMB int32  `json:"memoryMB" yaml:"memoryMB"`
+}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List templates",
+		Long: `Lists every template in the datacenter.
+
+Examples:
+  vcli template list
+  vcli template list -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			props := []string{"name", "config.template", "config.guestFullName", "config.hardware.numCPU", "config.hardware.memoryMB"}
+			vms, err := vsphere.ListVMs(ctx, c.Client, vsphere.DatacenterRef(global.DefaultDatacenterMoid), props)
+			if err != nil {
+				return err
+			}
+
+			templates := []templateInfo{}
+			for _, vm := range vms {
+				if vm.Config == nil || !vm.Config.Template {
+					continue
+				}
+				templates = append(templates, templateInfo{
+					Name:     vm.Name,
+					Moid:     vm.Reference().Value,
+					GuestOS:  vm.Config.GuestFullName,
+					CPUs:     vm.Config.Hardware.NumCPU,
+					MemoryMB: vm.Config.Hardware.MemoryMB,
+				})
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			headers := []string{"NAME", "MOID", "GUEST OS", "CPUS", "MEMORY (MB)"}
+			return formatter.Print(templates, headers, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, t := range data.([]templateInfo) {
+					rows = append(rows, []string{t.Name, t.Moid, t.GuestOS, fmt.Sprintf("%d", t.CPUs), fmt.Sprintf("%d", t.MemoryMB)})
+				}
+				return rows
+			})
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,25 @@
+package template
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewTemplateCmd creates the template command
+func NewTemplateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Manage VM templates",
+		Long: `Manage VM templates used as golden images for cloning.
+
+Available subcommands:
+  list                 - List templates
+  convert-to-template  - Mark a powered-off VM as a template
+  convert-to-vm        - Turn a template back into a VM`,
+	}
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newConvertToTemplateCmd())
+	cmd.AddCommand(newConvertToVMCmd())
+
+	return cmd
+}
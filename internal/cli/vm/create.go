@@ -0,0 +1,222 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"gopkg.in/yaml.v3"
+
+	"github.com/spf13/cobra"
+)
+
+// createSpec is the YAML spec file accepted by vm create. Its fields mirror
+// the command flags, which take precedence over the file.
+type createSpec struct {
+	Name           string   `yaml:"name"`
+	GuestID        string   `yaml:"guestId"`
+	CPUs           int32    `yaml:"cpus"`
+	CoresPerSocket int32    `yaml:"coresPerSocket"`
+	MemoryMB       int64    `yaml:"memoryMB"`
+	Firmware       string   `yaml:"firmware"`
+	Disks          []string `yaml:"disks"`
+	Thin           bool     `yaml:"thin"`
+	DiskController string   `yaml:"diskController"`
+	Networks       []string `yaml:"networks"`
+	NICType        string   `yaml:"nicType"`
+	ISO            string   `yaml:"iso"`
+	Placement      struct {
+		Datastore    string `yaml:"datastore"`
+		Folder       string `yaml:"folder"`
+		Cluster      string `yaml:"cluster"`
+		Host         string `yaml:"host"`
+		ResourcePool string `yaml:"resourcePool"`
+	} `yaml:"placement"`
+	PowerOn bool `yaml:"powerOn"`
+}
+
+var (
+	createFile string
+	createFlag createSpec
+)
+
+func newCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a blank VM",
+		Long: `Creates a new VM from scratch, with empty disks, network adapters and an
+optional ISO attached as a CD-ROM, e.g. to run an OS installer.
+
+The VM can be described with flags, with a YAML spec file (--file), or both;
+flags given on the command line override the file. Sizes take an M, G or T
+suffix (binary units); a bare number is in GB.
+
+Example spec file:
+  name: installer-test
+  guestId: rhel9_64Guest
+  cpus: 2
+  memoryMB: 4096
+  firmware: efi
+  disks: [40G, 10G]
+  networks: [VM Network]
+  iso: "[iso-store] rhel-9.4-x86_64-dvd.iso"
+  placement:
+    datastore: datastore1
+    cluster: prod
+    folder: installers
+  powerOn: true
+
+Examples:
+  vcli vm create test-1 --datastore datastore1 --guest-id ubuntu64Guest --disk 20G --network "VM Network"
+  vcli vm create test-2 --datastore datastore1 --iso "[iso-store] ubuntu-24.04.iso" --power-on
+  vcli vm create --file installer.yaml
+  vcli vm create test-3 --file installer.yaml --memory 8192`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			spec, err := loadCreateSpec(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			disks := make([]int64, 0, len(spec.Disks))
+			for _, s := range spec.Disks {
+				size, err := parseSize(s)
+				if err != nil {
+					return fmt.Errorf("disk: %w", err)
+				}
+				disks = append(disks, size)
+			}
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			p := spec.Placement
+			place, err := vsphere.ResolvePlacement(ctx, c.Client, global.DefaultDatacenterMoid, global.DefaultClusterMoid, p.Folder, p.Cluster, p.Host, p.ResourcePool)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.CreateVM(ctx, c.Client, global.DefaultDatacenterMoid, vsphere.CreateSpec{
+				Name:           spec.Name,
+				GuestID:        spec.GuestID,
+				CPUs:           spec.CPUs,
+				CoresPerSocket: spec.CoresPerSocket,
+				MemoryMB:       spec.MemoryMB,
+				Firmware:       spec.Firmware,
+				Disks:          disks,
+				Thin:           spec.Thin,
+				DiskController: spec.DiskController,
+				Networks:       spec.Networks,
+				NICType:        spec.NICType,
+				ISO:            spec.ISO,
+				Datastore:      p.Datastore,
+			}, place)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", spec.Name, err)
+			}
+
+			fmt.Printf("VM %s created (%s)\n", spec.Name, vm.Reference().Value)
+
+			if spec.PowerOn {
+				if err := vsphere.PowerOn(ctx, vm); err != nil {
+					return fmt.Errorf("VM created but failed to power on: %w", err)
+				}
+				fmt.Printf("VM %s powered on\n", spec.Name)
+			}
+
+			return nil
+		},
+	}
+
+	f := &createFlag
+	cmd.Flags().StringVarP(&createFile, "file", "f", "", "YAML spec file describing the VM")
+	cmd.Flags().StringVar(&f.GuestID, "guest-id", "otherGuest64", "Guest OS identifier (e.g. ubuntu64Guest, rhel9_64Guest, windows2019srv_64Guest)")
+	cmd.Flags().Int32Var(&f.CPUs, "cpus", 2, "Number of virtual CPUs")
+	cmd.Flags().Int32Var(&f.CoresPerSocket, "cores-per-socket", 0, "Number of cores per CPU socket")
+	cmd.Flags().Int64Var(&f.MemoryMB, "memory", 4096, "Memory in MB")
+	cmd.Flags().StringVar(&f.Firmware, "firmware", "", "Firmware: bios or efi (vSphere default if omitted)")
+	cmd.Flags().StringArrayVar(&f.Disks, "disk", []string{"40G"}, "Size of a disk to create (repeatable)")
+	cmd.Flags().BoolVar(&f.Thin, "thin", true, "Thin provision the disks")
+	cmd.Flags().StringVar(&f.DiskController, "disk-controller", "pvscsi", "SCSI controller type (pvscsi, lsilogic, lsilogic-sas, buslogic)")
+	cmd.Flags().StringArrayVar(&f.Networks, "network", nil, "Network to connect a NIC to (repeatable)")
+	cmd.Flags().StringVar(&f.NICType, "nic-type", "vmxnet3", "Adapter type of the NICs (vmxnet3, e1000e, e1000)")
+	cmd.Flags().StringVar(&f.ISO, "iso", "", "Datastore path of an ISO to attach, e.g. \"[iso-store] os.iso\"")
+	cmd.Flags().StringVar(&f.Placement.Datastore, "datastore", "", "Datastore for the VM files and disks")
+	cmd.Flags().StringVar(&f.Placement.Folder, "folder", "", "VM folder to create the VM in (datacenter VM folder if omitted)")
+	cmd.Flags().StringVar(&f.Placement.Cluster, "cluster", "", "Cluster to create the VM in")
+	cmd.Flags().StringVar(&f.Placement.Host, "esxi-host", "", "ESXi host to create the VM on")
+	cmd.Flags().StringVar(&f.Placement.ResourcePool, "resource-pool", "", "Resource pool to create the VM in")
+	cmd.Flags().BoolVar(&f.PowerOn, "power-on", false, "Power on the VM after creating it")
+
+	return cmd
+}
+
+// loadCreateSpec merges the spec file, the command flags and the name
+// argument, in increasing order of precedence
+func loadCreateSpec(cmd *cobra.Command, args []string) (*createSpec, error) {
+	spec := createFlag
+	if createFile != "" {
+		data, err := os.ReadFile(createFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// Start from the flag defaults so the file only needs what it changes
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			return nil, fmt.Errorf("invalid spec file %s: %w", createFile, err)
+		}
+
+		flags := cmd.Flags()
+		override := map[string]func(){
+			"guest-id":         func() { spec.GuestID = createFlag.GuestID },
+			"cpus":             func() { spec.CPUs = createFlag.CPUs },
+			"cores-per-socket": func() { spec.CoresPerSocket = createFlag.CoresPerSocket },
+			"memory":           func() { spec.MemoryMB = createFlag.MemoryMB },
+			"firmware":         func() { spec.Firmware = createFlag.Firmware },
+			"disk":             func() { spec.Disks = createFlag.Disks },
+			"thin":             func() { spec.Thin = createFlag.Thin },
+			"disk-controller":  func() { spec.DiskController = createFlag.DiskController },
+			"network":          func() { spec.Networks = createFlag.Networks },
+			"nic-type":         func() { spec.NICType = createFlag.NICType },
+			"iso":              func() { spec.ISO = createFlag.ISO },
+			"datastore":        func() { spec.Placement.Datastore = createFlag.Placement.Datastore },
+			"folder":           func() { spec.Placement.Folder = createFlag.Placement.Folder },
+			"cluster":          func() { spec.Placement.Cluster = createFlag.Placement.Cluster },
+			"esxi-host":        func() { spec.Placement.Host = createFlag.Placement.Host },
+			"resource-pool":    func() { spec.Placement.ResourcePool = createFlag.Placement.ResourcePool },
+			"power-on":         func() { spec.PowerOn = createFlag.PowerOn },
+		}
+		for name, apply := range override {
+			if flags.Changed(name) {
+				apply()
+			}
+		}
+	}
+
+	if len(args) == 1 {
+		spec.Name = args[0]
+	}
+
+	switch {
+	case spec.Name == "":
+		return nil, fmt.Errorf("specify the VM name as an argument or in the spec file")
+	case spec.Placement.Datastore == "":
+		return nil, fmt.Errorf("--datastore is required")
+	case spec.Firmware != "" && spec.Firmware != "bios" && spec.Firmware != "efi":
+		return nil, fmt.Errorf("invalid firmware %q (must be bios or efi)", spec.Firmware)
+	}
+
+	return &spec, nil
+}
@@ -0,0 +1,126 @@
+package vm
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/internal/prompt"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	destroyYes      bool
+	destroyPowerOff bool
+	destroyTimeout  time.Duration
+)
+
+func newDestroyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "destroy <vm>",
+		Short: "Delete a VM and its files",
+		Long: `Deletes a VM from the inventory and removes its files from the datastore.
+
+A powered-on or suspended VM is refused unless --power-off is given, in which
+case the guest is first asked to shut down through VMware Tools and the VM is
+powered off hard if it is still running after --timeout.
+
+A confirmation prompt is shown unless --yes is given.
+
+Examples:
+  vcli vm destroy test-1
+  vcli vm destroy test-1 --power-off --yes
+  vcli vm destroy /dc1/vm/installers/test-2 --power-off --timeout 30s`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			props, err := vsphere.RetrieveVM(ctx, c.Client, vm.Reference(), []string{"name", "runtime.powerState", "summary.config.template", "summary.storage.committed"})
+			if err != nil {
+				return err
+			}
+
+			state := props.Runtime.PowerState
+			if state != types.VirtualMachinePowerStatePoweredOff && !destroyPowerOff {
+				return fmt.Errorf("%s is %s; use --power-off to power it off before destroying it", props.Name, state)
+			}
+
+			if !destroyYes {
+				path, err := find.InventoryPath(ctx, c.Client, vm.Reference())
+				if err != nil {
+					path = props.Name
+				}
+				kind := "VM"
+				if props.Summary.Config.Template {
+					kind = "template"
+				}
+				var committed int64
+				if props.Summary.Storage != nil {
+					committed = props.Summary.Storage.Committed
+				}
+
+				fmt.Fprintf(os.Stderr, "The following %s will be deleted with its files:\n", kind)
+				fmt.Fprintf(os.Stderr, "  %s (%s, %s, %s on disk)\n", path, vm.Reference().Value, state, output.FormatBytes(committed))
+
+				ok, err := prompt.Confirm(os.Stdin, os.Stderr, fmt.Sprintf("Destroy %s?", props.Name))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			switch state {
+			case types.VirtualMachinePowerStatePoweredOn:
+				forced, err := vsphere.Shutdown(ctx, vm, destroyTimeout, true, true)
+				if err != nil {
+					return fmt.Errorf("failed to power off %s: %w", props.Name, err)
+				}
+				if forced {
+					fmt.Fprintf(os.Stderr, "Guest did not shut down cleanly, powered off %s hard\n", props.Name)
+				}
+			case types.VirtualMachinePowerStateSuspended:
+				if err := vsphere.PowerOff(ctx, vm); err != nil {
+					return fmt.Errorf("failed to power off %s: %w", props.Name, err)
+				}
+			}
+
+			if err := vsphere.DestroyVM(ctx, vm); err != nil {
+				return fmt.Errorf("failed to destroy %s: %w", props.Name, err)
+			}
+
+			fmt.Printf("VM %s destroyed\n", props.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&destroyYes, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&destroyPowerOff, "power-off", false, "Power off the VM first if it is running")
+	cmd.Flags().DurationVar(&destroyTimeout, "timeout", 2*time.Minute, "How long to wait for a guest shutdown before powering off hard")
+
+	return cmd
+}
@@ -0,0 +1,238 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	listFolder     string
+	listCluster    string
+	listHost       string
+	listPowerState string
+	listName       string
+	listTag        string
+	listGuestOS    string
+	listColumns    string
+)
+
+const defaultListColumns = "name,power,cpus,memory,guest-os,ip,host"
+
+// listColumn is a selectable column of vm list and the property it needs
+type listColumn struct {
+	header string
+	prop   string
+	value  func(vsphere.VMListEntry) string
+}
+
+var listColumnDefs = map[string]listColumn{
+	"name":          {"NAME", "", func(e vsphere.VMListEntry) string { return e.Name }},
+	"moid":          {"MOID", "", func(e vsphere.VMListEntry) string { return e.Moid }},
+	"power":         {"POWER", vsphere.VMPropPowerState, func(e vsphere.VMListEntry) string { return e.PowerState }},
+	"cpus":          {"CPUS", vsphere.VMPropCPUs, func(e vsphere.VMListEntry) string { return fmt.Sprintf("%d", e.CPUs) }},
+	"memory":        {"MEMORY (MB)", vsphere.VMPropMemory, func(e vsphere.VMListEntry) string { return fmt.Sprintf("%d", e.MemoryMB) }},
+	"guest-id":      {"GUEST ID", vsphere.VMPropGuestID, func(e vsphere.VMListEntry) string { return e.GuestID }},
+	"guest-os":      {"GUEST OS", vsphere.VMPropGuestOS, func(e vsphere.VMListEntry) string { return e.GuestOS }},
+	"ip":            {"IP", vsphere.VMPropIPAddress, func(e vsphere.VMListEntry) string { return e.IPAddress }},
+	"tools":         {"TOOLS", vsphere.VMPropTools, func(e vsphere.VMListEntry) string { return e.Tools }},
+	"host":          {"HOST", vsphere.VMPropHost, func(e vsphere.VMListEntry) string { return e.Host }},
+	"cluster":       {"CLUSTER", vsphere.VMPropHost, func(e vsphere.VMListEntry) string { return e.Cluster }},
+	"folder":        {"FOLDER", vsphere.VMPropParent, func(e vsphere.VMListEntry) string { return e.Folder }},
+	"uuid":          {"UUID", vsphere.VMPropUUID, func(e vsphere.VMListEntry) string { return e.UUID }},
+	"instance-uuid": {"INSTANCE UUID", vsphere.VMPropInstanceUUID, func(e vsphere.VMListEntry) string { return e.InstanceUUID }},
+	"storage":       {"STORAGE", vsphere.VMPropCommitted, func(e vsphere.VMListEntry) string { return output.FormatBytes(e.CommittedBytes) }},
+}
+
+// listColumnOrder is the order in which columns are documented
+var listColumnOrder = []string{"name", "moid", "power", "cpus", "memory", "guest-id", "guest-os", "ip", "tools", "host", "cluster", "folder", "uuid", "instance-uuid", "storage"}
+
+func newListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List VMs with filters",
+		Long: `Lists the VMs in the datacenter, optionally filtered. Templates are not
+listed; use 'vcli template list' for those.
+
+All properties are fetched with a single PropertyCollector query, and only
+the properties needed by the filters and the selected columns are requested,
+so listing stays fast on large inventories.
+
+Filters:
+  --folder       Only VMs under this VM folder (e.g. prod/web)
+  --cluster      Only VMs running on hosts of this cluster
+  --esxi-host    Only VMs running on this ESXi host
+  --power-state  on, off or suspended
+  --name         Shell glob, or a regular expression wrapped in slashes (/^web-\d+$/)
+  --tag          vSphere tag name, or category:name
+  --guest-os     Case-insensitive glob matched against the guest ID and full name
+
+Columns (--columns, comma-separated):
+  ` + strings.Join(listColumnOrder, ", ") + `
+
+Examples:
+  vcli vm list
+  vcli vm list --cluster prod --power-state on
+  vcli vm list --name 'web-*' --columns name,ip,host,cluster
+  vcli vm list --name '/^db-[0-9]+$/' --guest-os '*linux*'
+  vcli vm list --tag env:production -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			columns, props, err := parseColumns(listColumns)
+			if err != nil {
+				return err
+			}
+
+			filter := vsphere.VMFilter{Root: vsphere.DatacenterRef(global.DefaultDatacenterMoid)}
+			if listPowerState != "" {
+				if filter.PowerState, err = parsePowerState(listPowerState); err != nil {
+					return err
+				}
+			}
+			if listName != "" {
+				if filter.Name, err = vsphere.NameMatcher(listName); err != nil {
+					return err
+				}
+			}
+			filter.GuestOS = listGuestOS
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			finder := vsphere.NewFinder(c.Client, global.DefaultDatacenterMoid)
+
+			if listFolder != "" {
+				folder, err := vsphere.VMFolder(ctx, c.Client, global.DefaultDatacenterMoid, listFolder)
+				if err != nil {
+					return fmt.Errorf("folder %q: %w", listFolder, err)
+				}
+				filter.Root = folder.Reference()
+			}
+
+			if listCluster != "" {
+				cluster, err := finder.ClusterComputeResource(ctx, listCluster)
+				if err != nil {
+					return fmt.Errorf("cluster %q: %w", listCluster, err)
+				}
+				var cr mo.ClusterComputeResource
+				if err := property.DefaultCollector(c.Client).RetrieveOne(ctx, cluster.Reference(), []string{"host"}, &cr); err != nil {
+					return err
+				}
+				filter.Hosts = make(map[types.ManagedObjectReference]bool, len(cr.Host))
+				for _, h := range cr.Host {
+					filter.Hosts[h] = true
+				}
+			}
+
+			if listHost != "" {
+				host, err := finder.HostSystem(ctx, listHost)
+				if err != nil {
+					return fmt.Errorf("host %q: %w", listHost, err)
+				}
+				// With --cluster as well, the host must be one of the cluster's
+				inCluster := filter.Hosts == nil || filter.Hosts[host.Reference()]
+				filter.Hosts = map[types.ManagedObjectReference]bool{}
+				if inCluster {
+					filter.Hosts[host.Reference()] = true
+				}
+			}
+
+			if listTag != "" {
+				if filter.VMs, err = vsphere.TaggedVMs(ctx, c.Client, cfg.Username, cfg.Password, listTag); err != nil {
+					return err
+				}
+			}
+
+			entries, err := vsphere.FilterVMs(ctx, c.Client, filter, props)
+			if err != nil {
+				return err
+			}
+
+			headers := make([]string, len(columns))
+			for i, col := range columns {
+				headers[i] = col.header
+			}
+
+			formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+			return formatter.Print(entries, headers, func(data interface{}) [][]string {
+				rows := [][]string{}
+				for _, e := range data.([]vsphere.VMListEntry) {
+					row := make([]string, len(columns))
+					for i, col := range columns {
+						row[i] = col.value(e)
+					}
+					rows = append(rows, row)
+				}
+				return rows
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&listFolder, "folder", "", "Only list VMs under this VM folder")
+	cmd.Flags().StringVar(&listCluster, "cluster", "", "Only list VMs running in this cluster")
+	cmd.Flags().StringVar(&listHost, "esxi-host", "", "Only list VMs running on this ESXi host")
+	cmd.Flags().StringVar(&listPowerState, "power-state", "", "Only list VMs in this power state (on, off, suspended)")
+	cmd.Flags().StringVar(&listName, "name", "", "Only list VMs whose name matches this glob or /regex/")
+	cmd.Flags().StringVar(&listTag, "tag", "", "Only list VMs carrying this tag (name or category:name)")
+	cmd.Flags().StringVar(&listGuestOS, "guest-os", "", "Only list VMs whose guest OS matches this glob")
+	cmd.Flags().StringVar(&listColumns, "columns", defaultListColumns, "Comma-separated columns to show")
+
+	return cmd
+}
+
+// parseColumns resolves the --columns value into column definitions and the
+// VM properties they need
+func parseColumns(s string) ([]listColumn, []string, error) {
+	var columns []listColumn
+	var props []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := listColumnDefs[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown column %q (valid: %s)", name, strings.Join(listColumnOrder, ", "))
+		}
+		columns = append(columns, col)
+		if col.prop != "" {
+			props = append(props, col.prop)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("--columns must name at least one column")
+	}
+	return columns, props, nil
+}
+
+// parsePowerState accepts both the short (on) and API (poweredOn) spellings
+func parsePowerState(s string) (types.VirtualMachinePowerState, error) {
+	switch strings.ToLower(s) {
+	case "on", "poweredon":
+		return types.VirtualMachinePowerStatePoweredOn, nil
+	case "off", "poweredoff":
+		return types.VirtualMachinePowerStatePoweredOff, nil
+	case "suspended":
+		return types.VirtualMachinePowerStateSuspended, nil
+	}
+	return "", fmt.Errorf("invalid power state %q (must be on, off or suspended)", s)
+}
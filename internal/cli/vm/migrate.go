@@ -0,0 +1,211 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateHost       string
+	migrateCluster    string
+	migratePool       string
+	migrateDatastore  string
+	migrateDiskFormat string
+	migrateSkipChecks bool
+	migrateCheckOnly  bool
+)
+
+func newMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate <vm>",
+		Short: "Move a VM to another host, cluster, resource pool or datastore",
+		Long: `Relocates a VM: a compute move (vMotion) with --host, --cluster or
+--resource-pool, a storage move (Storage vMotion) with --datastore, or both
+at once. Powered-off VMs are cold-migrated.
+
+With --cluster alone, the VM goes to the cluster's root resource pool and DRS
+picks the host. With --host alone, the VM keeps its resource pool when the host is in its
+current cluster, and goes to the root pool of the host's cluster otherwise.
+
+--disk-format converts every disk during a storage move: thin, thick (lazy
+zeroed) or eager-zeroed. It requires --datastore.
+
+Before moving, the vSphere provisioning and compatibility checks are run
+against the target; errors abort the move and warnings are printed.
+--check-only runs the checks without moving the VM, and --skip-checks moves
+it without running them.
+
+Examples:
+  vcli vm migrate my-vm --host esx-02.example.com
+  vcli vm migrate my-vm --cluster prod-b
+  vcli vm migrate my-vm --datastore ssd-01 --disk-format thin
+  vcli vm migrate my-vm --host esx-05 --datastore ssd-02
+  vcli vm migrate my-vm --cluster prod-b --check-only`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if migrateHost == "" && migrateCluster == "" && migratePool == "" && migrateDatastore == "" {
+				return fmt.Errorf("specify at least one of --host, --cluster, --resource-pool or --datastore")
+			}
+			if migrateSkipChecks && migrateCheckOnly {
+				return fmt.Errorf("--skip-checks and --check-only are mutually exclusive")
+			}
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			target, err := migrateTarget(ctx, c.Client, vm)
+			if err != nil {
+				return err
+			}
+			target.DiskFormat = migrateDiskFormat
+
+			spec, err := vsphere.RelocateSpec(ctx, vm, *target)
+			if err != nil {
+				return err
+			}
+
+			if !migrateSkipChecks {
+				issues, err := vsphere.CheckRelocate(ctx, c.Client, vm, spec)
+				if err != nil {
+					return fmt.Errorf("pre-checks failed: %w (use --skip-checks to move without them)", err)
+				}
+
+				if migrateCheckOnly {
+					formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+					if err := formatter.Print(issues, []string{"LEVEL", "MESSAGE"}, func(data interface{}) [][]string {
+						rows := [][]string{}
+						for _, i := range data.([]vsphere.CheckIssue) {
+							rows = append(rows, []string{i.Level, i.Message})
+						}
+						return rows
+					}); err != nil {
+						return err
+					}
+				}
+
+				var errs []string
+				for _, i := range issues {
+					if i.Level == "error" {
+						errs = append(errs, i.Message)
+					} else if !migrateCheckOnly {
+						fmt.Fprintf(os.Stderr, "Warning: %s\n", i.Message)
+					}
+				}
+				if len(errs) > 0 {
+					return fmt.Errorf("%s cannot be moved to the target:\n  %s", args[0], strings.Join(errs, "\n  "))
+				}
+				if migrateCheckOnly {
+					fmt.Fprintf(os.Stderr, "%s can be moved to the target\n", args[0])
+					return nil
+				}
+			}
+
+			fromHost, fromDatastores, err := vsphere.VMLocation(ctx, c.Client, vm)
+			if err != nil {
+				return err
+			}
+
+			sink := vsphere.NewProgress(os.Stderr, "Migrating "+args[0])
+			err = vsphere.Relocate(ctx, vm, spec, sink)
+			sink.Wait()
+			if err != nil {
+				return fmt.Errorf("failed to migrate %s: %w", args[0], err)
+			}
+
+			toHost, toDatastores, err := vsphere.VMLocation(ctx, c.Client, vm)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Migrated %s\n", args[0])
+			fmt.Printf("  host:       %s -> %s\n", fromHost, toHost)
+			fmt.Printf("  datastores: %s -> %s\n", strings.Join(fromDatastores, ", "), strings.Join(toDatastores, ", "))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&migrateHost, "host", "", "Host to move the VM to")
+	cmd.Flags().StringVar(&migrateCluster, "cluster", "", "Cluster to move the VM to (DRS picks the host)")
+	cmd.Flags().StringVar(&migratePool, "resource-pool", "", "Resource pool to move the VM to")
+	cmd.Flags().StringVar(&migrateDatastore, "datastore", "", "Datastore to move the VM files and disks to")
+	cmd.Flags().StringVar(&migrateDiskFormat, "disk-format", "", "Convert disks during the storage move: thin, thick or eager-zeroed")
+	cmd.Flags().BoolVar(&migrateSkipChecks, "skip-checks", false, "Move without running the vSphere pre-checks")
+	cmd.Flags().BoolVar(&migrateCheckOnly, "check-only", false, "Run the vSphere pre-checks without moving the VM")
+
+	return cmd
+}
+
+// migrateTarget resolves the target flags. The resource pool is taken from
+// --resource-pool, else from the root pool of --cluster, or of the cluster
+// of --host when the VM leaves its current cluster, so that compute moves
+// across clusters work.
+func migrateTarget(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) (*vsphere.RelocateTarget, error) {
+	finder := vsphere.NewFinder(c, global.DefaultDatacenterMoid)
+
+	var err error
+	target := &vsphere.RelocateTarget{}
+	if migrateHost != "" {
+		if target.Host, err = finder.HostSystem(ctx, migrateHost); err != nil {
+			return nil, fmt.Errorf("host %q: %w", migrateHost, err)
+		}
+	}
+	if migrateDatastore != "" {
+		if target.Datastore, err = finder.Datastore(ctx, migrateDatastore); err != nil {
+			return nil, fmt.Errorf("datastore %q: %w", migrateDatastore, err)
+		}
+	}
+
+	switch {
+	case migratePool != "":
+		if target.Pool, err = finder.ResourcePool(ctx, migratePool); err != nil {
+			return nil, fmt.Errorf("resource pool %q: %w", migratePool, err)
+		}
+	case migrateCluster != "":
+		cluster, err := finder.ClusterComputeResource(ctx, migrateCluster)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q: %w", migrateCluster, err)
+		}
+		if target.Pool, err = cluster.ResourcePool(ctx); err != nil {
+			return nil, err
+		}
+	case target.Host != nil:
+		leaves, err := vsphere.LeavesComputeResource(ctx, c, vm, target.Host)
+		if err != nil {
+			return nil, err
+		}
+		if !leaves {
+			break
+		}
+		if target.Pool, err = target.Host.ResourcePool(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return target, nil
+}
@@ -0,0 +1,220 @@
+package vm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/asegev/vsphere-cli/internal/cli/inspect"
+	"github.com/asegev/vsphere-cli/internal/global"
+	"github.com/asegev/vsphere-cli/pkg/config"
+	"github.com/asegev/vsphere-cli/pkg/output"
+	"github.com/asegev/vsphere-cli/pkg/vsphere"
+	vmware "github.com/kubev2v/assisted-migration-agent/pkg/vmware"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconfigureCPUs        int32
+	reconfigureCores       int32
+	reconfigureMemory      int64
+	reconfigureAddDisks    []string
+	reconfigureResizeDisks []string
+	reconfigureRemoveDisks []string
+	reconfigureDeleteFiles bool
+	reconfigureAddNICs     []string
+	reconfigureNICType     string
+	reconfigureRemoveNICs  []string
+	reconfigureSetNetworks []string
+	reconfigureExtraConfig []string
+	reconfigureAnnotation  string
+	reconfigureDryRun      bool
+	reconfigureColor       string
+)
+
+func newReconfigureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconfigure <vm>",
+		Short: "Change the hardware and settings of a VM",
+		Long: `Changes CPU, memory, disks, network adapters, advanced settings and the
+annotation of a VM in a single reconfigure task.
+
+CPU and memory changes on a powered-on VM are checked against its hot-add
+settings first: adding CPUs or memory requires hot-add to be enabled, and
+memory cannot be reduced until the VM is powered off.
+
+Disks and network adapters are referred to by their label, as shown by
+'vcli inspect disks' or 'vcli inspect vm' (e.g. "Hard disk 2", "Network
+adapter 1"). New disks are thin provisioned in the VM's home datastore.
+Sizes take an M, G or T suffix (binary units); a bare number is in GB.
+
+--dry-run prints the changes the computed config spec would make, as a diff
+against the current configuration, without applying it.
+
+Examples:
+  vcli vm reconfigure my-vm --cpus 4 --memory 8192
+  vcli vm reconfigure my-vm --add-disk 50G --resize-disk "Hard disk 1=100G"
+  vcli vm reconfigure my-vm --remove-disk "Hard disk 3" --delete-files
+  vcli vm reconfigure my-vm --add-nic VM-Network --set-network "Network adapter 1=pg-prod"
+  vcli vm reconfigure my-vm --extra-config disk.EnableUUID=TRUE --annotation "owned by team-a"
+  vcli vm reconfigure my-vm --cpus 8 --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			opts, err := reconfigureOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.LoadFromEnv()
+			if err != nil {
+				return err
+			}
+
+			c, err := vmware.NewVsphereClient(ctx, cfg.Host, cfg.Username, cfg.Password, cfg.Insecure)
+			if err != nil {
+				return err
+			}
+
+			vm, err := vsphere.ResolveVM(ctx, c.Client, global.DefaultDatacenterMoid, args[0])
+			if err != nil {
+				return err
+			}
+
+			plan, err := vsphere.PlanReconfigure(ctx, c.Client, global.DefaultDatacenterMoid, vm, opts)
+			if err != nil {
+				return err
+			}
+
+			if len(plan.Changes) == 0 {
+				fmt.Printf("%s already matches the requested configuration\n", args[0])
+				return nil
+			}
+
+			if reconfigureDryRun {
+				formatter := output.NewFormatter(output.Format(cmd.Flag("output").Value.String()))
+				return formatter.PrintDiff(plan.Changes, reconfigureColor, func(data interface{}) (string, string, []output.DiffLine) {
+					return args[0] + " (current)", args[0] + " (planned)", inspect.DiffLines(data.([]vsphere.Change))
+				})
+			}
+
+			if err := vsphere.ApplyReconfigure(ctx, vm, plan); err != nil {
+				return fmt.Errorf("failed to reconfigure %s: %w", args[0], err)
+			}
+
+			fmt.Printf("Reconfigured %s (%d changes)\n", args[0], len(plan.Changes))
+			return nil
+		},
+	}
+
+	cmd.Flags().Int32Var(&reconfigureCPUs, "cpus", 0, "Number of virtual CPUs")
+	cmd.Flags().Int32Var(&reconfigureCores, "cores-per-socket", 0, "Number of cores per CPU socket")
+	cmd.Flags().Int64Var(&reconfigureMemory, "memory", 0, "Memory in MB")
+	cmd.Flags().StringArrayVar(&reconfigureAddDisks, "add-disk", nil, "Add a thin disk of this size (repeatable)")
+	cmd.Flags().StringArrayVar(&reconfigureResizeDisks, "resize-disk", nil, "Grow a disk, as \"<label>=<size>\" (repeatable)")
+	cmd.Flags().StringArrayVar(&reconfigureRemoveDisks, "remove-disk", nil, "Detach the disk with this label (repeatable)")
+	cmd.Flags().BoolVar(&reconfigureDeleteFiles, "delete-files", false, "Delete the files of removed disks")
+	cmd.Flags().StringArrayVar(&reconfigureAddNICs, "add-nic", nil, "Add a network adapter on this network (repeatable)")
+	cmd.Flags().StringVar(&reconfigureNICType, "nic-type", "vmxnet3", "Adapter type of added NICs (vmxnet3, e1000e, e1000)")
+	cmd.Flags().StringArrayVar(&reconfigureRemoveNICs, "remove-nic", nil, "Remove the network adapter with this label (repeatable)")
+	cmd.Flags().StringArrayVar(&reconfigureSetNetworks, "set-network", nil, "Move a NIC to another network, as \"<label>=<network>\" (repeatable)")
+	cmd.Flags().StringArrayVar(&reconfigureExtraConfig, "extra-config", nil, "Set an advanced setting, as \"key=value\"; an empty value removes it (repeatable)")
+	cmd.Flags().StringVar(&reconfigureAnnotation, "annotation", "", "Set the VM annotation (notes)")
+	cmd.Flags().BoolVar(&reconfigureDryRun, "dry-run", false, "Print the planned changes without applying them")
+	cmd.Flags().StringVar(&reconfigureColor, "color", output.ColorAuto, "Color the --dry-run diff: auto, always or never")
+
+	return cmd
+}
+
+// reconfigureOptions turns the command flags into reconfigure options
+func reconfigureOptions(cmd *cobra.Command) (vsphere.ReconfigureOptions, error) {
+	opts := vsphere.ReconfigureOptions{
+		CPUs:            reconfigureCPUs,
+		CoresPerSocket:  reconfigureCores,
+		MemoryMB:        reconfigureMemory,
+		RemoveDisks:     reconfigureRemoveDisks,
+		DeleteDiskFiles: reconfigureDeleteFiles,
+		AddNICs:         reconfigureAddNICs,
+		NICType:         reconfigureNICType,
+		RemoveNICs:      reconfigureRemoveNICs,
+	}
+
+	for _, s := range reconfigureAddDisks {
+		size, err := parseSize(s)
+		if err != nil {
+			return opts, fmt.Errorf("--add-disk: %w", err)
+		}
+		opts.AddDisks = append(opts.AddDisks, size)
+	}
+
+	if len(reconfigureResizeDisks) > 0 {
+		pairs, err := parsePairs("--resize-disk", reconfigureResizeDisks, false)
+		if err != nil {
+			return opts, err
+		}
+		opts.ResizeDisks = make(map[string]int64, len(pairs))
+		for label, s := range pairs {
+			size, err := parseSize(s)
+			if err != nil {
+				return opts, fmt.Errorf("--resize-disk %s: %w", label, err)
+			}
+			opts.ResizeDisks[label] = size
+		}
+	}
+
+	var err error
+	if opts.SetNetworks, err = parsePairs("--set-network", reconfigureSetNetworks, false); err != nil {
+		return opts, err
+	}
+	if opts.ExtraConfig, err = parsePairs("--extra-config", reconfigureExtraConfig, true); err != nil {
+		return opts, err
+	}
+
+	if cmd.Flags().Changed("annotation") {
+		opts.Annotation = &reconfigureAnnotation
+	}
+
+	return opts, nil
+}
+
+// parsePairs parses repeated "key=value" flag values
+func parsePairs(flag string, values []string, allowEmpty bool) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	pairs := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || (value == "" && !allowEmpty) {
+			return nil, fmt.Errorf("%s: expected key=value, got %q", flag, v)
+		}
+		pairs[key] = value
+	}
+	return pairs, nil
+}
+
+// parseSize parses a size such as "512M", "40G" or "2T" into bytes. A bare
+// number is in GB.
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+
+	unit := int64(1 << 30)
+	switch {
+	case strings.HasSuffix(s, "M"):
+		unit, s = 1<<20, strings.TrimSuffix(s, "M")
+	case strings.HasSuffix(s, "G"):
+		s = strings.TrimSuffix(s, "G")
+	case strings.HasSuffix(s, "T"):
+		unit, s = 1<<40, strings.TrimSuffix(s, "T")
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * unit, nil
+}
@@ -0,0 +1,29 @@
+package vm
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewVMCmd creates the vm command
+func NewVMCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vm",
+		Short: "Manage virtual machines",
+		Long: `List and manage virtual machines.
+
+Available subcommands:
+  list         - List VMs with filters
+  reconfigure  - Change the hardware and settings of a VM
+  create       - Create a blank VM
+  destroy      - Delete a VM and its files
+  migrate      - Move a VM to another host, cluster, resource pool or datastore`,
+	}
+
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newReconfigureCmd())
+	cmd.AddCommand(newCreateCmd())
+	cmd.AddCommand(newDestroyCmd())
+	cmd.AddCommand(newMigrateCmd())
+
+	return cmd
+}
@@ -76,3 +76,28 @@ func MaskPassword(password string) string {
 	}
 	return password[:2] + "••••••" + password[len(password)-2:]
 }
+
+// GuestCredentials authenticate guest operations inside a VM
+type GuestCredentials struct {
+	Username string
+	Password string
+}
+
+// LoadGuestFromEnv loads guest operation credentials from environment variables
+func LoadGuestFromEnv() *GuestCredentials {
+	return &GuestCredentials{
+		Username: os.Getenv("VCLI_GUEST_USERNAME"),
+		Password: os.Getenv("VCLI_GUEST_PASSWORD"),
+	}
+}
+
+// Validate checks if guest credentials are present
+func (g *GuestCredentials) Validate() error {
+	if g.Username == "" {
+		return fmt.Errorf("VCLI_GUEST_USERNAME is required for guest operations")
+	}
+	if g.Password == "" {
+		return fmt.Errorf("VCLI_GUEST_PASSWORD is required for guest operations")
+	}
+	return nil
+}
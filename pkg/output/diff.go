@@ -0,0 +1,83 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DiffLine is one line of a unified diff. Op is '-', '+' or '@' for hunk headers.
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// Color modes accepted by PrintDiff
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+const (
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiCyan  = "\033[36m"
+	ansiBold  = "\033[1m"
+	ansiReset = "\033[0m"
+)
+
+// PrintDiff renders data as a unified diff in table mode, coloring it
+// according to color. Other formats print data as-is.
+func (f *Formatter) PrintDiff(data interface{}, color string, diffFunc func(interface{}) (string, string, []DiffLine)) error {
+	if f.format != FormatTable {
+		return f.Print(data, nil, nil)
+	}
+
+	var useColor bool
+	switch color {
+	case ColorAlways:
+		useColor = true
+	case ColorNever:
+		useColor = false
+	case ColorAuto, "":
+		useColor = isTerminal(f.writer)
+	default:
+		return fmt.Errorf("invalid color mode %q (must be auto, always or never)", color)
+	}
+
+	from, to, lines := diffFunc(data)
+	paint := func(code, s string) string {
+		if !useColor {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	fmt.Fprintln(f.writer, paint(ansiBold, "--- "+from))
+	fmt.Fprintln(f.writer, paint(ansiBold, "+++ "+to))
+	for _, l := range lines {
+		switch l.Op {
+		case '-':
+			fmt.Fprintln(f.writer, paint(ansiRed, "-"+l.Text))
+		case '+':
+			fmt.Fprintln(f.writer, paint(ansiGreen, "+"+l.Text))
+		case '@':
+			fmt.Fprintln(f.writer, paint(ansiCyan, "@@ "+l.Text+" @@"))
+		default:
+			fmt.Fprintln(f.writer, " "+l.Text)
+		}
+	}
+
+	return nil
+}
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"text/template"
 
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v3"
@@ -14,23 +16,78 @@ import (
 type Format string
 
 const (
-	FormatTable Format = "table"
-	FormatJSON  Format = "json"
-	FormatYAML  Format = "yaml"
+	FormatTable      Format = "table"
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatJSONPath   Format = "jsonpath"
+	FormatGoTemplate Format = "go-template"
 )
 
 // Formatter handles output formatting
 type Formatter struct {
-	format Format
-	writer io.Writer
+	format   Format
+	writer   io.Writer
+	jsonPath *jsonPath
+	template *template.Template
+	// err is why the format is invalid, returned by every Print call
+	err error
 }
 
-// NewFormatter creates a new formatter
+// ParseFormat validates an --output value. Besides the plain formats it
+// accepts kubectl-style "jsonpath=<template>" and "go-template=<template>".
+func ParseFormat(s string) (Format, error) {
+	if _, err := newFormatter(Format(s)); err != nil {
+		return "", err
+	}
+	return Format(s), nil
+}
+
+// NewFormatter creates a new formatter. The root command validates --output
+// with ParseFormat; should an invalid format get here anyway, printing fails
+// with the validation error rather than falling back to another format.
 func NewFormatter(format Format) *Formatter {
-	return &Formatter{
+	f, err := newFormatter(format)
+	if err != nil {
+		return &Formatter{format: format, writer: os.Stdout, err: err}
+	}
+	return f
+}
+
+func newFormatter(format Format) (*Formatter, error) {
+	f := &Formatter{
 		format: format,
 		writer: os.Stdout,
 	}
+
+	name, tmpl, hasTemplate := strings.Cut(string(format), "=")
+	switch Format(name) {
+	case FormatTable, FormatJSON, FormatYAML:
+		if hasTemplate {
+			return nil, fmt.Errorf("output format %s does not take a template", name)
+		}
+	case FormatJSONPath:
+		if tmpl == "" {
+			return nil, fmt.Errorf("jsonpath output requires a template, e.g. -o jsonpath='{.name}'")
+		}
+		jp, err := parseJSONPath(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath template: %w", err)
+		}
+		f.format, f.jsonPath = FormatJSONPath, jp
+	case FormatGoTemplate:
+		if tmpl == "" {
+			return nil, fmt.Errorf("go-template output requires a template, e.g. -o go-template='{{.Name}}'")
+		}
+		t, err := template.New("output").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		f.format, f.template = FormatGoTemplate, t
+	default:
+		return nil, fmt.Errorf("invalid output format: %s (must be table, json, yaml, jsonpath=... or go-template=...)", format)
+	}
+
+	return f, nil
 }
 
 // SetWriter sets the output writer (useful for testing)
@@ -66,11 +123,19 @@ func (f *Formatter) PrintTable(headers []string, rows [][]string) {
 
 // Print outputs data based on the configured format
 func (f *Formatter) Print(data interface{}, headers []string, rowFunc func(interface{}) [][]string) error {
+	if f.err != nil {
+		return f.err
+	}
+
 	switch f.format {
 	case FormatJSON:
 		return f.PrintJSON(data)
 	case FormatYAML:
 		return f.PrintYAML(data)
+	case FormatJSONPath:
+		return f.jsonPath.Execute(f.writer, data)
+	case FormatGoTemplate:
+		return f.template.Execute(f.writer, data)
 	case FormatTable:
 		if rowFunc != nil {
 			rows := rowFunc(data)
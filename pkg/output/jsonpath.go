@@ -0,0 +1,527 @@
+package output
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed kubectl-style JSONPath template such as
+// '{.name}' or '{range [*]}{.name}{"\n"}{end}'.
+//
+// Supported syntax: plain text, field access (.field), array indexes ([0],
+// [-1]), slices ([1:3], [-2:]), wildcards ([*] or .*), filters
+// ([?(@.state=="on")], [?(@.size>10)], [?(@.ip)]), quoted literals ({"\t"})
+// and range/end blocks.
+type jsonPath struct {
+	nodes []jpNode
+}
+
+type jpNodeKind int
+
+const (
+	jpText jpNodeKind = iota
+	jpPath
+	jpRange
+)
+
+type jpNode struct {
+	kind  jpNodeKind
+	text  string
+	steps []jpStep
+	body  []jpNode
+}
+
+// jpStep is one step of a path: a field name, an index, a slice, a
+// wildcard or a filter
+type jpStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+	slice    *jpSlice
+	filter   *jpFilter
+}
+
+// jpSlice is an array slice; nil bounds mean the start or end of the array
+type jpSlice struct {
+	start, end *int
+}
+
+// jpFilter keeps the array elements for which the path relative to the
+// element (@) exists, or compares with value when op is set
+type jpFilter struct {
+	steps []jpStep
+	op    string
+	value interface{}
+}
+
+// jpOperators are the filter comparisons, two-character ones first so they
+// are matched before their prefixes
+var jpOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseJSONPath parses a JSONPath template
+func parseJSONPath(tmpl string) (*jsonPath, error) {
+	nodes, rest, err := parseJPNodes(tmpl, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected {end}")
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parseJPNodes parses until the end of input or, inside a range, until {end}.
+// It returns the unparsed remainder following {end}.
+func parseJPNodes(s string, inRange bool) ([]jpNode, string, error) {
+	var nodes []jpNode
+
+	for s != "" {
+		open := strings.Index(s, "{")
+		if open < 0 {
+			nodes = append(nodes, jpNode{kind: jpText, text: s})
+			s = ""
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jpNode{kind: jpText, text: s[:open]})
+		}
+
+		end := jpIndexUnquoted(s, open+1, jpByte('}'))
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed expression in %q", s[open:])
+		}
+		expr := strings.TrimSpace(s[open+1 : end])
+		s = s[end+1:]
+
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("unexpected {end}")
+			}
+			return nodes, s, nil
+		case strings.HasPrefix(expr, "range "):
+			steps, err := parseJPSteps(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJPNodes(s, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpNode{kind: jpRange, steps: steps, body: body})
+			s = rest
+		case isQuoted(expr):
+			text, err := unquoteLiteral(expr)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid literal %s: %w", expr, err)
+			}
+			nodes = append(nodes, jpNode{kind: jpText, text: text})
+		default:
+			steps, err := parseJPSteps(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jpNode{kind: jpPath, steps: steps})
+		}
+	}
+
+	if inRange {
+		return nil, "", fmt.Errorf("{range} is missing {end}")
+	}
+	return nodes, "", nil
+}
+
+// jpIndexUnquoted returns the first index from from onwards at which match
+// reports true, skipping quoted literals, or -1
+func jpIndexUnquoted(s string, from int, match func(rest string) bool) int {
+	var quote byte
+	for i := from; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case match(s[i:]):
+			return i
+		}
+	}
+	return -1
+}
+
+// jpByte matches a single byte for jpIndexUnquoted
+func jpByte(c byte) func(string) bool {
+	return func(rest string) bool { return rest[0] == c }
+}
+
+func isQuoted(expr string) bool {
+	if len(expr) < 2 {
+		return false
+	}
+	first, last := expr[0], expr[len(expr)-1]
+	return (first == '"' || first == '\'') && first == last
+}
+
+// unquoteLiteral interprets a single- or double-quoted literal with Go escapes
+func unquoteLiteral(expr string) (string, error) {
+	if expr[0] == '\'' {
+		expr = `"` + strings.ReplaceAll(expr[1:len(expr)-1], `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(expr)
+}
+
+// parseJPSteps parses a path such as .network[0].ip or [*].name
+func parseJPSteps(path string) ([]jpStep, error) {
+	path = strings.TrimPrefix(path, "$")
+
+	var steps []jpStep
+	for path != "" {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			n := strings.IndexAny(path, ".[")
+			if n < 0 {
+				n = len(path)
+			}
+			field := path[:n]
+			path = path[n:]
+			switch field {
+			case "":
+				// "." alone refers to the current value
+			case "*":
+				steps = append(steps, jpStep{wildcard: true})
+			default:
+				steps = append(steps, jpStep{field: field})
+			}
+		case '[':
+			end := jpIndexUnquoted(path, 1, jpByte(']'))
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in path %q", path)
+			}
+			sub := strings.TrimSpace(path[1:end])
+			path = path[end+1:]
+			if sub == "*" {
+				steps = append(steps, jpStep{wildcard: true})
+				continue
+			}
+			if isQuoted(sub) {
+				steps = append(steps, jpStep{field: sub[1 : len(sub)-1]})
+				continue
+			}
+			if strings.HasPrefix(sub, "?") {
+				filter, err := parseJPFilter(sub)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, jpStep{filter: filter})
+				continue
+			}
+			if strings.Contains(sub, ":") {
+				slice, err := parseJPSlice(sub)
+				if err != nil {
+					return nil, err
+				}
+				steps = append(steps, jpStep{slice: slice})
+				continue
+			}
+			i, err := strconv.Atoi(sub)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index [%s]", sub)
+			}
+			steps = append(steps, jpStep{index: i, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected . or [", path)
+		}
+	}
+
+	return steps, nil
+}
+
+// parseJPSlice parses "start:end" with either bound optional
+func parseJPSlice(sub string) (*jpSlice, error) {
+	parts := strings.Split(sub, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid slice [%s]: only [start:end] is supported", sub)
+	}
+
+	slice := &jpSlice{}
+	for i, bound := range []**int{&slice.start, &slice.end} {
+		p := strings.TrimSpace(parts[i])
+		if p == "" {
+			continue
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice [%s]", sub)
+		}
+		*bound = &n
+	}
+	return slice, nil
+}
+
+// parseJPFilter parses "?(@.path)" or "?(@.path <op> <literal>)"
+func parseJPFilter(sub string) (*jpFilter, error) {
+	expr := strings.TrimSpace(strings.TrimPrefix(sub, "?"))
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return nil, fmt.Errorf("invalid filter [%s]: expected ?(...)", sub)
+	}
+	expr = strings.TrimSpace(expr[1 : len(expr)-1])
+
+	var op string
+	left, right := expr, ""
+	i := jpIndexUnquoted(expr, 0, func(rest string) bool {
+		for _, o := range jpOperators {
+			if strings.HasPrefix(rest, o) {
+				op = o
+				return true
+			}
+		}
+		return false
+	})
+	if i >= 0 {
+		left, right = strings.TrimSpace(expr[:i]), strings.TrimSpace(expr[i+len(op):])
+	}
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("invalid filter [%s]: the left side must start with @", sub)
+	}
+	steps, err := parseJPSteps(strings.TrimPrefix(left, "@"))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &jpFilter{steps: steps, op: op}
+	if op == "" {
+		return filter, nil
+	}
+
+	switch {
+	case isQuoted(right):
+		s, err := unquoteLiteral(right)
+		if err != nil {
+			return nil, fmt.Errorf("invalid literal %s: %w", right, err)
+		}
+		filter.value = s
+	case right == "true" || right == "false":
+		filter.value = right == "true"
+	default:
+		n, err := strconv.ParseFloat(right, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter [%s]: %q is not a string, number or boolean", sub, right)
+		}
+		filter.value = n
+	}
+	return filter, nil
+}
+
+// Execute writes the template evaluated against data, which is first
+// converted to its JSON representation so paths follow the JSON field names
+func (p *jsonPath) Execute(w io.Writer, data interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var root interface{}
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return err
+	}
+
+	return executeJPNodes(w, p.nodes, root)
+}
+
+func executeJPNodes(w io.Writer, nodes []jpNode, current interface{}) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case jpText:
+			if _, err := io.WriteString(w, n.text); err != nil {
+				return err
+			}
+		case jpPath:
+			values, err := evalJPSteps(n.steps, current)
+			if err != nil {
+				return err
+			}
+			parts := make([]string, 0, len(values))
+			for _, v := range values {
+				s, err := jpString(v)
+				if err != nil {
+					return err
+				}
+				parts = append(parts, s)
+			}
+			if _, err := io.WriteString(w, strings.Join(parts, " ")); err != nil {
+				return err
+			}
+		case jpRange:
+			values, err := evalJPSteps(n.steps, current)
+			if err != nil {
+				return err
+			}
+			// Ranging over a single array iterates its elements
+			if len(values) == 1 {
+				if arr, ok := values[0].([]interface{}); ok {
+					values = arr
+				}
+			}
+			for _, v := range values {
+				if err := executeJPNodes(w, n.body, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func evalJPSteps(steps []jpStep, current interface{}) ([]interface{}, error) {
+	values := []interface{}{current}
+
+	for _, step := range steps {
+		var next []interface{}
+		for _, v := range values {
+			switch {
+			case step.wildcard:
+				switch t := v.(type) {
+				case []interface{}:
+					next = append(next, t...)
+				case map[string]interface{}:
+					for _, k := range slices.Sorted(maps.Keys(t)) {
+						next = append(next, t[k])
+					}
+				}
+			case step.isIndex:
+				arr, ok := v.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot index [%d]: value is not an array", step.index)
+				}
+				i := step.index
+				if i < 0 {
+					i += len(arr)
+				}
+				if i < 0 || i >= len(arr) {
+					return nil, fmt.Errorf("array index [%d] out of range (length %d)", step.index, len(arr))
+				}
+				next = append(next, arr[i])
+			case step.slice != nil:
+				arr, ok := v.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot slice: value is not an array")
+				}
+				start, end := step.slice.bounds(len(arr))
+				next = append(next, arr[start:end]...)
+			case step.filter != nil:
+				arr, ok := v.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot filter: value is not an array")
+				}
+				for _, elem := range arr {
+					if step.filter.match(elem) {
+						next = append(next, elem)
+					}
+				}
+			default:
+				obj, ok := v.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("cannot look up %q: value is not an object", step.field)
+				}
+				field, ok := obj[step.field]
+				if !ok {
+					return nil, fmt.Errorf("%s is not found", step.field)
+				}
+				next = append(next, field)
+			}
+		}
+		values = next
+	}
+
+	return values, nil
+}
+
+// bounds resolves the slice against an array of length n, counting negative
+// bounds from the end and clamping both to the array like Python does
+func (s *jpSlice) bounds(n int) (int, int) {
+	resolve := func(b *int, def int) int {
+		if b == nil {
+			return def
+		}
+		i := *b
+		if i < 0 {
+			i += n
+		}
+		return min(max(i, 0), n)
+	}
+	start, end := resolve(s.start, 0), resolve(s.end, n)
+	return start, max(start, end)
+}
+
+// match reports whether an array element passes the filter. Elements that
+// lack the path, or whose value has a different type than the literal, do
+// not match.
+func (f *jpFilter) match(elem interface{}) bool {
+	values, err := evalJPSteps(f.steps, elem)
+	if err != nil || len(values) == 0 {
+		return false
+	}
+	if f.op == "" {
+		return true
+	}
+
+	switch want := f.value.(type) {
+	case string:
+		return jpCompare(values[0], want, f.op)
+	case float64:
+		return jpCompare(values[0], want, f.op)
+	case bool:
+		got, ok := values[0].(bool)
+		return ok && (f.op == "==" && got == want || f.op == "!=" && got != want)
+	}
+	return false
+}
+
+// jpCompare applies a filter comparison to a value of the literal's type
+func jpCompare[T cmp.Ordered](v interface{}, want T, op string) bool {
+	got, ok := v.(T)
+	if !ok {
+		return false
+	}
+
+	c := cmp.Compare(got, want)
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// jpString renders a JSON value: strings verbatim, everything else as JSON
+func jpString(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return t, nil
+	default:
+		raw, err := json.Marshal(t)
+		return string(raw), err
+	}
+}
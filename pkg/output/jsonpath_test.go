@@ -0,0 +1,183 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type jpDisk struct {
+	Label  string `json:"label"`
+	SizeGB int    `json:"sizeGB"`
+	Thin   bool   `json:"thin"`
+}
+
+type jpVM struct {
+	Name  string            `json:"name"`
+	State string            `json:"state"`
+	IP    string            `json:"ip,omitempty"`
+	Disks []jpDisk          `json:"disks"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+var jpTestData = []jpVM{
+	{
+		Name:  "web-1",
+		State: "poweredOn",
+		IP:    "10.0.0.1",
+		Disks: []jpDisk{{Label: "Hard disk 1", SizeGB: 40, Thin: true}, {Label: "Hard disk 2", SizeGB: 200}},
+		Tags:  map[string]string{"env": "prod", "app": "web"},
+	},
+	{
+		Name:  "db-1",
+		State: "poweredOff",
+		Disks: []jpDisk{{Label: "Hard disk 1", SizeGB: 80}},
+	},
+	{
+		Name:  "cache-1",
+		State: "poweredOn",
+		IP:    "10.0.0.3",
+		Disks: []jpDisk{},
+	},
+}
+
+func TestJSONPathExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"plain text", "hello", "hello"},
+		{"field", "{[0].name}", "web-1"},
+		{"nested field", "{[0].disks[1].label}", "Hard disk 2"},
+		{"number", "{[0].disks[0].sizeGB}", "40"},
+		{"boolean", "{[0].disks[0].thin}", "true"},
+		{"object as JSON", "{[1].disks[0]}", `{"label":"Hard disk 1","sizeGB":80,"thin":false}`},
+		{"root dollar", "{$[1].name}", "db-1"},
+		{"quoted field", "{[0]['name']}", "web-1"},
+		{"negative index", "{[-1].name}", "cache-1"},
+		{"wildcard", "{[*].name}", "web-1 db-1 cache-1"},
+		{"dot wildcard sorts keys", "{[0].tags.*}", "web prod"},
+		{"literals", `{[0].name}{"\t"}{[1].name}{'\n'}`, "web-1\tdb-1\n"},
+		{"literal with brace", `{"}"}`, "}"},
+
+		{"slice", "{[0:2].name}", "web-1 db-1"},
+		{"slice open end", "{[1:].name}", "db-1 cache-1"},
+		{"slice open start", "{[:1].name}", "web-1"},
+		{"slice negative", "{[-2:].name}", "db-1 cache-1"},
+		{"slice clamped", "{[1:10].name}", "db-1 cache-1"},
+		{"slice empty", "{[2:1].name}", ""},
+
+		{"filter equals", `{[?(@.state=="poweredOn")].name}`, "web-1 cache-1"},
+		{"filter not equals", `{[?(@.state!="poweredOn")].name}`, "db-1"},
+		{"filter single quotes", `{[?(@.state == 'poweredOff')].name}`, "db-1"},
+		{"filter exists", "{[?(@.ip)].name}", "web-1 cache-1"},
+		{"filter number", "{[0].disks[?(@.sizeGB>40)].label}", "Hard disk 2"},
+		{"filter number or equal", "{[0].disks[?(@.sizeGB>=40)].label}", "Hard disk 1 Hard disk 2"},
+		{"filter less than", "{[*].disks[?(@.sizeGB<100)].sizeGB}", "40 80"},
+		{"filter boolean", "{[*].disks[?(@.thin==true)].label}", "Hard disk 1"},
+		{"filter type mismatch", `{[?(@.state==1)].name}`, ""},
+		{"filter literal with bracket", `{[?(@.name=="a]b")].name}`, ""},
+
+		{"range", `{range [*]}{.name}{"\n"}{end}`, "web-1\ndb-1\ncache-1\n"},
+		{"range over field", `{range [0].disks}{.label},{end}`, "Hard disk 1,Hard disk 2,"},
+		{"range over filter", `{range [?(@.ip)]}{.name}={.ip} {end}`, "web-1=10.0.0.1 cache-1=10.0.0.3 "},
+		{"nested range", `{range [*]}{.name}:{range .disks}{.sizeGB} {end};{end}`, "web-1:40 200 ;db-1:80 ;cache-1:;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jp, err := parseJSONPath(tt.tmpl)
+			if err != nil {
+				t.Fatalf("parseJSONPath(%q): %v", tt.tmpl, err)
+			}
+			var out strings.Builder
+			if err := jp.Execute(&out, jpTestData); err != nil {
+				t.Fatalf("Execute(%q): %v", tt.tmpl, err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Execute(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONPathParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{"unclosed expression", "{.name", "unclosed expression"},
+		{"stray end", "{end}", "unexpected {end}"},
+		{"range without end", "{range [*]}{.name}", "missing {end}"},
+		{"unclosed bracket", "{[0}", "unclosed ["},
+		{"invalid index", "{[x]}", "invalid array index"},
+		{"invalid path", "{name}", "expected . or ["},
+		{"invalid literal", `{"\q"}`, "invalid literal"},
+		{"slice with step", "{[0:4:2]}", "only [start:end]"},
+		{"invalid slice bound", "{[a:2]}", "invalid slice"},
+		{"filter without parentheses", "{[?@.name]}", "expected ?(...)"},
+		{"filter without @", `{[?(name=="x")]}`, "must start with @"},
+		{"filter bad literal", "{[?(@.name==web)]}", "not a string, number or boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJSONPath(tt.tmpl)
+			if err == nil {
+				t.Fatalf("parseJSONPath(%q) succeeded, want error containing %q", tt.tmpl, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseJSONPath(%q) error = %q, want it to contain %q", tt.tmpl, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONPathExecuteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{"missing field", "{[0].missing}", "missing is not found"},
+		{"index out of range", "{[5].name}", "out of range"},
+		{"index on object", "{[0].tags[0]}", "not an array"},
+		{"field on array", "{.name}", "not an object"},
+		{"slice on object", "{[0].tags[0:1]}", "cannot slice"},
+		{"filter on object", "{[0].tags[?(@.env)]}", "cannot filter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jp, err := parseJSONPath(tt.tmpl)
+			if err != nil {
+				t.Fatalf("parseJSONPath(%q): %v", tt.tmpl, err)
+			}
+			var out strings.Builder
+			err = jp.Execute(&out, jpTestData)
+			if err == nil {
+				t.Fatalf("Execute(%q) succeeded, want error containing %q", tt.tmpl, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute(%q) error = %q, want it to contain %q", tt.tmpl, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewFormatterInvalid(t *testing.T) {
+	f := NewFormatter(Format("jsonpath={.name"))
+	var out strings.Builder
+	f.SetWriter(&out)
+
+	err := f.Print(jpTestData, []string{"NAME"}, func(interface{}) [][]string {
+		return [][]string{{"web-1"}}
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid jsonpath template") {
+		t.Fatalf("Print with an invalid format: err = %v, want the validation error", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Print with an invalid format wrote %q, want nothing", out.String())
+	}
+}
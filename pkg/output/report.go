@@ -0,0 +1,107 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Section is a titled block of key/value lines in a sectioned text report
+type Section struct {
+	Title  string
+	Fields []Field
+}
+
+// Field is a single "Key: Value" line of a section.
+// Indent nests the line under the field before it.
+type Field struct {
+	Key    string
+	Value  string
+	Indent int
+}
+
+// AddField appends a field to the section
+func (s *Section) AddField(key, value string) {
+	s.Fields = append(s.Fields, Field{Key: key, Value: value})
+}
+
+// AddNested appends a field indented under the previous one
+func (s *Section) AddNested(key, value string) {
+	s.Fields = append(s.Fields, Field{Key: key, Value: value, Indent: 1})
+}
+
+// PrintSections outputs a sectioned text report under a heading
+func (f *Formatter) PrintSections(heading string, sections []Section) {
+	fmt.Fprintln(f.writer, heading)
+	fmt.Fprintln(f.writer, strings.Repeat("=", 80))
+
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Fprintln(f.writer)
+		}
+		fmt.Fprintf(f.writer, "%s:\n", s.Title)
+
+		w := tabwriter.NewWriter(f.writer, 0, 0, 2, ' ', 0)
+		for _, field := range s.Fields {
+			indent := strings.Repeat("  ", field.Indent+1)
+			fmt.Fprintf(w, "%s%s:\t%s\n", indent, field.Key, field.Value)
+		}
+		w.Flush()
+	}
+}
+
+// PrintReport outputs data as JSON or YAML, or as sectioned text in table format
+func (f *Formatter) PrintReport(data interface{}, sectionFunc func(interface{}) (string, []Section)) error {
+	if f.format != FormatTable {
+		return f.Print(data, nil, nil)
+	}
+
+	heading, sections := sectionFunc(data)
+	f.PrintSections(heading, sections)
+	return nil
+}
+
+// FormatBytes renders a byte count using binary units (e.g. "1.5 GB")
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+// FormatUsage renders used/total with a percentage, formatting values with format
+func FormatUsage(used, total int64, format func(int64) string) string {
+	if total == 0 {
+		return format(used)
+	}
+	return fmt.Sprintf("%s / %s (%.0f%%)", format(used), format(total), float64(used)*100/float64(total))
+}
+
+// FormatMHz renders a CPU frequency or usage in MHz
+func FormatMHz(v int64) string {
+	return fmt.Sprintf("%d MHz", v)
+}
+
+// FormatBool renders a flag as "yes" or "no"
+func FormatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// OrNone renders an empty value as "<none>" so table columns are never blank
+func OrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
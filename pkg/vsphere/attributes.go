@@ -0,0 +1,92 @@
+package vsphere
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Attributes reads and writes vCenter custom attributes by name
+type Attributes struct {
+	manager *object.CustomFieldsManager
+
+	mu    sync.Mutex
+	keys  map[string]int32
+	names map[int32]string
+}
+
+// NewAttributes loads the custom attribute definitions from vCenter.
+// Custom attributes are not available when connected directly to ESXi.
+func NewAttributes(ctx context.Context, c *vim25.Client) (*Attributes, error) {
+	m, err := object.GetCustomFieldsManager(c)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := m.Field(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Attributes{
+		manager: m,
+		keys:    make(map[string]int32, len(fields)),
+		names:   make(map[int32]string, len(fields)),
+	}
+	for _, f := range fields {
+		a.keys[f.Name] = f.Key
+		a.names[f.Key] = f.Name
+	}
+
+	return a, nil
+}
+
+// Set sets a custom attribute on a VM, defining the attribute first if needed
+func (a *Attributes) Set(ctx context.Context, ref types.ManagedObjectReference, name, value string) error {
+	key, err := a.key(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return a.manager.Set(ctx, ref, key, value)
+}
+
+// key returns the key of the named attribute, defining it on first use
+func (a *Attributes) key(ctx context.Context, name string) (int32, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if key, ok := a.keys[name]; ok {
+		return key, nil
+	}
+
+	def, err := a.manager.Add(ctx, name, "VirtualMachine", nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	a.keys[name] = def.Key
+	a.names[def.Key] = name
+
+	return def.Key, nil
+}
+
+// Values maps an entity's customValue property to attribute names
+func (a *Attributes) Values(values []types.BaseCustomFieldValue) map[string]string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m := make(map[string]string, len(values))
+	for _, v := range values {
+		sv, ok := v.(*types.CustomFieldStringValue)
+		if !ok {
+			continue
+		}
+		if name, ok := a.names[sv.Key]; ok {
+			m[name] = sv.Value
+		}
+	}
+	return m
+}
@@ -0,0 +1,242 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// CBTStatus is the Changed Block Tracking state of a VM and its disks
+type CBTStatus struct {
+	VM      string    `json:"vm" yaml:"vm"`
+	Enabled bool      `json:"enabled" yaml:"enabled"`
+	Disks   []CBTDisk `json:"disks" yaml:"disks"`
+}
+
+// CBTDisk is the CBT state of a single disk. Enabled reflects the per-disk
+// <node>.ctkEnabled setting; Active means the disk reports a change ID, which
+// only happens once CBT has been activated by a stun/unstun cycle.
+type CBTDisk struct {
+	Label    string `json:"label" yaml:"label"`
+	Key      int32  `json:"key" yaml:"key"`
+	Node     string `json:"node" yaml:"node"`
+	Enabled  bool   `json:"enabled" yaml:"enabled"`
+	Active   bool   `json:"active" yaml:"active"`
+	ChangeID string `json:"changeId,omitempty" yaml:"changeId,omitempty"`
+}
+
+// ChangedAreas lists the areas of a disk changed since a change ID
+type ChangedAreas struct {
+	Label         string     `json:"label" yaml:"label"`
+	Key           int32      `json:"key" yaml:"key"`
+	CapacityBytes int64      `json:"capacityBytes" yaml:"capacityBytes"`
+	ChangeID      string     `json:"changeId" yaml:"changeId"`
+	ChangedBytes  int64      `json:"changedBytes" yaml:"changedBytes"`
+	Areas         []DiskArea `json:"areas" yaml:"areas"`
+}
+
+// DiskArea is a changed extent of a disk, in bytes
+type DiskArea struct {
+	Start  int64 `json:"start" yaml:"start"`
+	Length int64 `json:"length" yaml:"length"`
+}
+
+// GetCBTStatus reads the VM-wide ctkEnabled flag and the per-disk flags
+func GetCBTStatus(ctx context.Context, vm *object.VirtualMachine) (*CBTStatus, error) {
+	cfg, err := VMConfig(ctx, vm)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := make(map[string]string)
+	for _, o := range cfg.ExtraConfig {
+		opt := o.GetOptionValue()
+		extra[strings.ToLower(opt.Key)] = fmt.Sprint(opt.Value)
+	}
+
+	status := &CBTStatus{
+		VM:      cfg.Name,
+		Enabled: boolValue(cfg.ChangeTrackingEnabled),
+		Disks:   []CBTDisk{},
+	}
+
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+	for _, d := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := d.(*types.VirtualDisk)
+		node := diskNode(devices, disk)
+		changeID := diskChangeID(disk)
+		status.Disks = append(status.Disks, CBTDisk{
+			Label:    deviceLabel(devices, disk.Key),
+			Key:      disk.Key,
+			Node:     node,
+			Enabled:  strings.EqualFold(extra[strings.ToLower(node+".ctkEnabled")], "true"),
+			Active:   changeID != "",
+			ChangeID: changeID,
+		})
+	}
+
+	return status, nil
+}
+
+// SetCBT enables or disables CBT on the VM and every disk. The change takes
+// effect after the next stun/unstun cycle (power on, snapshot create/remove).
+func SetCBT(ctx context.Context, vm *object.VirtualMachine, enabled bool) error {
+	cfg, err := VMConfig(ctx, vm)
+	if err != nil {
+		return err
+	}
+
+	value := "FALSE"
+	if enabled {
+		value = "TRUE"
+	}
+
+	spec := types.VirtualMachineConfigSpec{ChangeTrackingEnabled: types.NewBool(enabled)}
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+	for _, d := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		node := diskNode(devices, d.(*types.VirtualDisk))
+		if node == "" {
+			continue
+		}
+		spec.ExtraConfig = append(spec.ExtraConfig, &types.OptionValue{Key: node + ".ctkEnabled", Value: value})
+	}
+
+	task, err := vm.Reconfigure(ctx, spec)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// QueryChangedAreas returns the areas of each disk in the snapshot that
+// changed since changeID ("*" returns every allocated area). Only disks whose
+// label matches one of labels are queried, or all disks if labels is empty.
+// Change IDs belong to a single disk, so any other changeID than "*"
+// requires exactly one label.
+func QueryChangedAreas(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, snapshot, changeID string, labels []string) ([]ChangedAreas, error) {
+	if changeID != "*" && len(labels) != 1 {
+		return nil, fmt.Errorf("change ID %q belongs to a single disk: select exactly one disk", changeID)
+	}
+
+	snapRef, err := vm.FindSnapshot(ctx, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := SnapshotConfig(ctx, c, vm, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, l := range labels {
+		wanted[l] = true
+	}
+	found := make(map[string]bool)
+
+	results := []ChangedAreas{}
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+	for _, d := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := d.(*types.VirtualDisk)
+		label := deviceLabel(devices, disk.Key)
+		if len(wanted) > 0 && !wanted[label] {
+			continue
+		}
+		found[label] = true
+
+		res := ChangedAreas{
+			Label:         label,
+			Key:           disk.Key,
+			CapacityBytes: disk.CapacityInBytes,
+			ChangeID:      diskChangeID(disk),
+			Areas:         []DiskArea{},
+		}
+		if res.ChangeID == "" {
+			return nil, fmt.Errorf("%s has no change ID in snapshot %s: CBT was not active when it was taken", label, snapshot)
+		}
+
+		for offset := int64(0); offset < disk.CapacityInBytes; {
+			req := types.QueryChangedDiskAreas{
+				This:        vm.Reference(),
+				Snapshot:    snapRef,
+				DeviceKey:   disk.Key,
+				StartOffset: offset,
+				ChangeId:    changeID,
+			}
+			resp, err := methods.QueryChangedDiskAreas(ctx, c, &req)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query changed areas of %s: %w", label, err)
+			}
+
+			for _, area := range resp.Returnval.ChangedArea {
+				res.Areas = append(res.Areas, DiskArea{Start: area.Start, Length: area.Length})
+				res.ChangedBytes += area.Length
+			}
+
+			next := resp.Returnval.StartOffset + resp.Returnval.Length
+			if next <= offset {
+				break
+			}
+			offset = next
+		}
+
+		results = append(results, res)
+	}
+
+	for _, label := range labels {
+		if !found[label] {
+			return nil, fmt.Errorf("disk %q not found in snapshot %s", label, snapshot)
+		}
+	}
+
+	return results, nil
+}
+
+// diskNode returns the controller node of a disk, such as "scsi0:1", used
+// as the prefix of per-disk advanced settings
+func diskNode(devices object.VirtualDeviceList, disk *types.VirtualDisk) string {
+	if disk.UnitNumber == nil {
+		return ""
+	}
+	ctrl, ok := devices.FindByKey(disk.ControllerKey).(types.BaseVirtualController)
+	if !ok {
+		return ""
+	}
+
+	var prefix string
+	switch ctrl.(type) {
+	case types.BaseVirtualSCSIController:
+		prefix = "scsi"
+	case types.BaseVirtualSATAController:
+		prefix = "sata"
+	case *types.VirtualNVMEController:
+		prefix = "nvme"
+	case *types.VirtualIDEController:
+		prefix = "ide"
+	default:
+		return ""
+	}
+
+	return fmt.Sprintf("%s%d:%d", prefix, ctrl.GetVirtualController().BusNumber, *disk.UnitNumber)
+}
+
+// diskChangeID returns the CBT change ID of a disk backing, if any
+func diskChangeID(disk *types.VirtualDisk) string {
+	switch b := disk.Backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		return b.ChangeId
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		return b.ChangeId
+	case *types.VirtualDiskSeSparseBackingInfo:
+		return b.ChangeId
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		return b.ChangeId
+	default:
+		return ""
+	}
+}
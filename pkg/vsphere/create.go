@@ -0,0 +1,207 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// CreateSpec describes a VM built from scratch
+type CreateSpec struct {
+	Name           string
+	GuestID        string
+	CPUs           int32
+	CoresPerSocket int32
+	MemoryMB       int64
+	// Firmware is "bios" or "efi"; empty leaves the vSphere default
+	Firmware string
+	// Disks are the capacities in bytes of the disks to create, in order
+	Disks          []int64
+	Thin           bool
+	DiskController string
+	// Networks are the networks to connect a NIC of type NICType to, in order
+	Networks []string
+	NICType  string
+	// ISO is a datastore path such as "[iso] rhel-9.iso" to attach as a CD-ROM
+	ISO       string
+	Datastore string
+}
+
+// Placement is where a VM is created or moved to. Host may be nil, in which
+// case vSphere (or DRS) picks one from the pool.
+type Placement struct {
+	Folder *object.Folder
+	Pool   *object.ResourcePool
+	Host   *object.HostSystem
+}
+
+// VMFolder returns the VM folder at path, resolved against the datacenter's
+// VM folder unless it is absolute, or the VM folder itself when path is empty
+func VMFolder(ctx context.Context, c *vim25.Client, datacenterMoid, path string) (*object.Folder, error) {
+	finder := NewFinder(c, datacenterMoid)
+	if path == "" {
+		return finder.DefaultFolder(ctx)
+	}
+	if !strings.HasPrefix(path, "/") && path != "vm" && !strings.HasPrefix(path, "vm/") {
+		path = "vm/" + path
+	}
+	return finder.Folder(ctx, path)
+}
+
+// ResolvePlacement looks up a VM placement by name. The resource pool is,
+// in order of preference, pool, the root pool of cluster, the pool of the
+// host's cluster or the default cluster's root pool.
+func ResolvePlacement(ctx context.Context, c *vim25.Client, datacenterMoid, defaultClusterMoid, folder, cluster, host, pool string) (*Placement, error) {
+	finder := NewFinder(c, datacenterMoid)
+	p := &Placement{}
+
+	var err error
+	if p.Folder, err = VMFolder(ctx, c, datacenterMoid, folder); err != nil {
+		return nil, fmt.Errorf("folder %q: %w", folder, err)
+	}
+
+	if host != "" {
+		if p.Host, err = finder.HostSystem(ctx, host); err != nil {
+			return nil, fmt.Errorf("host %q: %w", host, err)
+		}
+	}
+
+	switch {
+	case pool != "":
+		if p.Pool, err = finder.ResourcePool(ctx, pool); err != nil {
+			return nil, fmt.Errorf("resource pool %q: %w", pool, err)
+		}
+	case cluster != "":
+		cr, err := finder.ClusterComputeResource(ctx, cluster)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q: %w", cluster, err)
+		}
+		if p.Pool, err = cr.ResourcePool(ctx); err != nil {
+			return nil, err
+		}
+	case p.Host != nil:
+		if p.Pool, err = p.Host.ResourcePool(ctx); err != nil {
+			return nil, err
+		}
+	default:
+		if p.Pool, err = ResourcePool(ctx, c, datacenterMoid, defaultClusterMoid, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// CreateVM creates a powered-off VM from spec at the given placement and
+// waits for the task to complete
+func CreateVM(ctx context.Context, c *vim25.Client, datacenterMoid string, spec CreateSpec, place *Placement) (*object.VirtualMachine, error) {
+	ds, err := NewFinder(c, datacenterMoid).Datastore(ctx, spec.Datastore)
+	if err != nil {
+		return nil, fmt.Errorf("datastore %q: %w", spec.Datastore, err)
+	}
+
+	devices, err := createDevices(ctx, c, datacenterMoid, spec, ds.Reference())
+	if err != nil {
+		return nil, err
+	}
+	deviceChange, err := devices.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range deviceChange {
+		if _, ok := change.GetVirtualDeviceConfigSpec().Device.(*types.VirtualDisk); ok {
+			change.GetVirtualDeviceConfigSpec().FileOperation = types.VirtualDeviceConfigSpecFileOperationCreate
+		}
+	}
+
+	config := types.VirtualMachineConfigSpec{
+		Name:              spec.Name,
+		GuestId:           spec.GuestID,
+		NumCPUs:           spec.CPUs,
+		NumCoresPerSocket: spec.CoresPerSocket,
+		MemoryMB:          spec.MemoryMB,
+		Firmware:          spec.Firmware,
+		DeviceChange:      deviceChange,
+		Files: &types.VirtualMachineFileInfo{
+			VmPathName: fmt.Sprintf("[%s]", ds.Name()),
+		},
+	}
+
+	task, err := place.Folder.CreateVM(ctx, config, place.Pool, place.Host)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.NewVirtualMachine(c, info.Result.(types.ManagedObjectReference)), nil
+}
+
+// createDevices builds the disk controller, disks, NICs and CD-ROM of a new VM
+func createDevices(ctx context.Context, c *vim25.Client, datacenterMoid string, spec CreateSpec, ds types.ManagedObjectReference) (object.VirtualDeviceList, error) {
+	var devices object.VirtualDeviceList
+
+	if len(spec.Disks) > 0 {
+		scsi, err := devices.CreateSCSIController(spec.DiskController)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, scsi)
+		controller := scsi.(types.BaseVirtualController)
+
+		for _, size := range spec.Disks {
+			disk := devices.CreateDisk(controller, ds, "")
+			disk.CapacityInBytes = size
+			disk.CapacityInKB = size / 1024
+			if !spec.Thin {
+				disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).ThinProvisioned = types.NewBool(false)
+			}
+			devices = append(devices, disk)
+		}
+	}
+
+	finder := NewFinder(c, datacenterMoid)
+	for _, name := range spec.Networks {
+		backing, err := networkBacking(ctx, finder, name)
+		if err != nil {
+			return nil, err
+		}
+		nic, err := devices.CreateEthernetCard(spec.NICType, backing)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, nic)
+	}
+
+	if spec.ISO != "" {
+		ide, err := devices.CreateIDEController()
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, ide)
+
+		cdrom, err := devices.CreateCdrom(ide.(*types.VirtualIDEController))
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, devices.InsertIso(cdrom, spec.ISO))
+	}
+
+	return devices, nil
+}
+
+// DestroyVM deletes a powered-off VM and its files and waits for the task to
+// complete
+func DestroyVM(ctx context.Context, vm *object.VirtualMachine) error {
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
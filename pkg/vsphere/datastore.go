@@ -0,0 +1,200 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DatastoreSummary is a datastore as shown by datastore list
+type DatastoreSummary struct {
+	Name            string `json:"name" yaml:"name"`
+	Moid            string `json:"moid" yaml:"moid"`
+	Type            string `json:"type" yaml:"type"`
+	CapacityBytes   int64  `json:"capacityBytes" yaml:"capacityBytes"`
+	FreeBytes       int64  `json:"freeBytes" yaml:"freeBytes"`
+	Accessible      bool   `json:"accessible" yaml:"accessible"`
+	MaintenanceMode string `json:"maintenanceMode,omitempty" yaml:"maintenanceMode,omitempty"`
+}
+
+// DatastoreFile is an entry of a datastore directory listing
+type DatastoreFile struct {
+	Name      string     `json:"name" yaml:"name"`
+	Path      string     `json:"path" yaml:"path"`
+	Type      string     `json:"type" yaml:"type"`
+	SizeBytes int64      `json:"sizeBytes" yaml:"sizeBytes"`
+	Modified  *time.Time `json:"modified,omitempty" yaml:"modified,omitempty"`
+}
+
+// ListDatastores returns the summary of every datastore under root, sorted by name
+func ListDatastores(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference) ([]DatastoreSummary, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"Datastore"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var stores []mo.Datastore
+	if err := v.Retrieve(ctx, []string{"Datastore"}, []string{"summary"}, &stores); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]DatastoreSummary, 0, len(stores))
+	for _, ds := range stores {
+		s := ds.Summary
+		summaries = append(summaries, DatastoreSummary{
+			Name:            s.Name,
+			Moid:            ds.Reference().Value,
+			Type:            s.Type,
+			CapacityBytes:   s.Capacity,
+			FreeBytes:       s.FreeSpace,
+			Accessible:      s.Accessible,
+			MaintenanceMode: s.MaintenanceMode,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	return summaries, nil
+}
+
+// ParseDatastorePath splits a "<datastore>:/path" or "[datastore] path"
+// argument into the datastore name and the path within it, without a
+// leading slash
+func ParseDatastorePath(s string) (string, string, error) {
+	var p object.DatastorePath
+	if strings.HasPrefix(s, "[") {
+		if !p.FromString(s) {
+			return "", "", fmt.Errorf("invalid datastore path %q", s)
+		}
+	} else {
+		ds, rest, ok := strings.Cut(s, ":")
+		if !ok || ds == "" {
+			return "", "", fmt.Errorf("invalid datastore path %q (expected <datastore>:/path)", s)
+		}
+		p.Datastore, p.Path = ds, rest
+	}
+
+	return p.Datastore, strings.Trim(path.Clean("/"+p.Path), "/"), nil
+}
+
+// ListDatastoreDir lists the directory at dir, or describes the single file
+// when dir is a file
+func ListDatastoreDir(ctx context.Context, ds *object.Datastore, dir string) ([]DatastoreFile, error) {
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := types.HostDatastoreBrowserSearchSpec{
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(false),
+		},
+		Query: []types.BaseFileQuery{
+			&types.FolderFileQuery{},
+			&types.VmDiskFileQuery{},
+			&types.IsoImageFileQuery{},
+			&types.VmConfigFileQuery{},
+			&types.VmLogFileQuery{},
+			&types.VmNvramFileQuery{},
+			&types.VmSnapshotFileQuery{},
+			&types.FileQuery{},
+		},
+		SortFoldersFirst: types.NewBool(true),
+	}
+
+	task, err := browser.SearchDatastore(ctx, ds.Path(dir), &spec)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		if !fault.Is(err, &types.FileNotFound{}) || dir == "" {
+			return nil, err
+		}
+		// Not a directory: describe the file itself
+		fi, statErr := ds.Stat(ctx, dir)
+		if statErr != nil {
+			return nil, err
+		}
+		return []DatastoreFile{DatastoreFileInfo(ds, dir, fi)}, nil
+	}
+
+	res := info.Result.(types.HostDatastoreBrowserSearchResults)
+	files := make([]DatastoreFile, 0, len(res.File))
+	for _, f := range res.File {
+		files = append(files, DatastoreFileInfo(ds, path.Join(dir, f.GetFileInfo().Path), f))
+	}
+	return files, nil
+}
+
+// DatastoreFileInfo converts the browser result for the file at p into a
+// DatastoreFile
+func DatastoreFileInfo(ds *object.Datastore, p string, f types.BaseFileInfo) DatastoreFile {
+	fi := f.GetFileInfo()
+	file := DatastoreFile{
+		Name:      path.Base(p),
+		Path:      ds.Path(p),
+		Type:      "file",
+		SizeBytes: fi.FileSize,
+		Modified:  fi.Modification,
+	}
+
+	switch f.(type) {
+	case *types.FolderFileInfo:
+		file.Type = "directory"
+	case *types.VmDiskFileInfo:
+		file.Type = "disk"
+	case *types.IsoImageFileInfo:
+		file.Type = "iso"
+	case *types.VmConfigFileInfo:
+		file.Type = "config"
+	case *types.VmLogFileInfo:
+		file.Type = "log"
+	case *types.VmNvramFileInfo:
+		file.Type = "nvram"
+	case *types.VmSnapshotFileInfo:
+		file.Type = "snapshot"
+	}
+
+	return file
+}
+
+// MakeDatastoreDir creates a directory on a datastore
+func MakeDatastoreDir(ctx context.Context, c *vim25.Client, datacenterMoid string, ds *object.Datastore, dir string, parents bool) error {
+	dc := object.NewDatacenter(c, DatacenterRef(datacenterMoid))
+	return object.NewFileManager(c).MakeDirectory(ctx, ds.Path(dir), dc, parents)
+}
+
+// RemoveDatastoreFile deletes a file or directory from a datastore. Virtual
+// disks are deleted through the virtual disk manager so their extents go
+// with them.
+func RemoveDatastoreFile(ctx context.Context, c *vim25.Client, datacenterMoid string, ds *object.Datastore, file string) error {
+	dc := object.NewDatacenter(c, DatacenterRef(datacenterMoid))
+
+	var task *object.Task
+	var err error
+	if strings.HasSuffix(file, ".vmdk") {
+		task, err = object.NewVirtualDiskManager(c).DeleteVirtualDisk(ctx, ds.Path(file), dc)
+	} else {
+		task, err = object.NewFileManager(c).DeleteDatastoreFile(ctx, ds.Path(file), dc)
+	}
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
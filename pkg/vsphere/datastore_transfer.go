@@ -0,0 +1,306 @@
+package vsphere
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// TransferOptions controls a datastore upload or download
+type TransferOptions struct {
+	// Resume continues a partial download, or skips an upload whose remote
+	// copy already has the local size and SHA-256. The datastore HTTP
+	// endpoint cannot append to a file, so a partial upload is always
+	// restarted.
+	Resume bool
+	// Verify re-reads the uploaded file from the datastore and compares
+	// its SHA-256 with the local file
+	Verify bool
+	// Checksum is the expected SHA-256 of the file, as hex with an optional
+	// "sha256:" prefix
+	Checksum string
+	Progress progress.Sinker
+}
+
+// TransferResult describes a completed upload or download
+type TransferResult struct {
+	Source      string `json:"source" yaml:"source"`
+	Destination string `json:"destination" yaml:"destination"`
+	SizeBytes   int64  `json:"sizeBytes" yaml:"sizeBytes"`
+	// TransferredBytes is less than SizeBytes when a download was resumed,
+	// and zero when the remote copy was already complete
+	TransferredBytes int64  `json:"transferredBytes" yaml:"transferredBytes"`
+	SHA256           string `json:"sha256" yaml:"sha256"`
+	Verified         bool   `json:"verified" yaml:"verified"`
+}
+
+// UploadDatastoreFile uploads a local file to a datastore path through the
+// HTTP file transfer endpoint
+func UploadDatastoreFile(ctx context.Context, ds *object.Datastore, local, remote string, opts TransferOptions) (*TransferResult, error) {
+	expected, err := parseChecksum(opts.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", local)
+	}
+
+	res := &TransferResult{Source: local, Destination: ds.Path(remote), SizeBytes: fi.Size()}
+
+	// Check the source before sending anything
+	if expected != "" {
+		if res.SHA256, err = hashReader(f); err != nil {
+			return nil, err
+		}
+		if res.SHA256 != expected {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", local, expected, res.SHA256)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	complete := false
+	if opts.Resume {
+		if rfi, err := ds.Stat(ctx, remote); err == nil && rfi.GetFileInfo().FileSize == fi.Size() {
+			// A matching size is not proof of a complete copy, so compare
+			// the contents before skipping the upload
+			if res.SHA256 == "" {
+				if res.SHA256, err = hashReader(f); err != nil {
+					return nil, err
+				}
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+			sum, err := hashRemote(ctx, ds, remote)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read back %s: %w", res.Destination, err)
+			}
+			complete = sum == res.SHA256
+			res.Verified = complete
+		}
+	}
+
+	if !complete {
+		h := sha256.New()
+		param := soap.DefaultUpload
+		param.ContentLength = fi.Size()
+		param.Progress = opts.Progress
+		if err := ds.Upload(ctx, io.TeeReader(f, h), remote, &param); err != nil {
+			return nil, err
+		}
+		res.TransferredBytes = fi.Size()
+		res.SHA256 = hex.EncodeToString(h.Sum(nil))
+	}
+
+	if opts.Verify && !res.Verified {
+		sum, err := hashRemote(ctx, ds, remote)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read back %s: %w", res.Destination, err)
+		}
+		if sum != res.SHA256 {
+			return nil, fmt.Errorf("verification failed for %s: local %s, remote %s", res.Destination, res.SHA256, sum)
+		}
+		res.Verified = true
+	}
+
+	return res, nil
+}
+
+// DownloadDatastoreFile downloads a datastore path to a local file through
+// the HTTP file transfer endpoint, resuming a partial local file with a
+// range request when opts.Resume is set
+func DownloadDatastoreFile(ctx context.Context, ds *object.Datastore, remote, local string, opts TransferOptions) (*TransferResult, error) {
+	expected, err := parseChecksum(opts.Checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TransferResult{Source: ds.Path(remote), Destination: local}
+
+	var offset int64
+	if opts.Resume {
+		if fi, err := os.Stat(local); err == nil && !fi.IsDir() {
+			offset = fi.Size()
+		}
+	}
+
+	u, ticket, err := ds.ServiceTicket(ctx, remote, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	param := soap.DefaultDownload
+	if ticket != nil {
+		param.Ticket = ticket
+		param.Close = true
+	}
+	if offset > 0 {
+		param.Headers = map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)}
+	}
+
+	resp, err := ds.Client().DownloadRequest(ctx, u, &param)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	h := sha256.New()
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// No range requested, or the server ignored it: start over
+		offset = 0
+		res.SizeBytes = resp.ContentLength
+	case http.StatusPartialContent:
+		res.SizeBytes, err = contentRangeSize(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+		if err := hashFile(h, local, offset); err != nil {
+			return nil, err
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The range starts at or past the end of the remote file; it is
+		// only complete when the local file is exactly as large
+		rfi, err := ds.Stat(ctx, remote)
+		if err != nil {
+			return nil, err
+		}
+		if size := rfi.GetFileInfo().FileSize; size != offset {
+			return nil, fmt.Errorf("local file %s is %d bytes but %s is %d bytes; remove it and download again", local, offset, res.Source, size)
+		}
+		if err := hashFile(h, local, offset); err != nil {
+			return nil, err
+		}
+		res.SizeBytes = offset
+		return finishDownload(res, h, expected)
+	default:
+		return nil, fmt.Errorf("download(%s): %s", res.Source, resp.Status)
+	}
+
+	out, err := os.OpenFile(local, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = resp.Body
+	done := func(error) {}
+	if opts.Progress != nil {
+		pr := progress.NewReader(ctx, opts.Progress, body, res.SizeBytes-offset)
+		body, done = pr, pr.Done
+	}
+
+	n, err := io.Copy(io.MultiWriter(out, h), body)
+	done(err)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	res.TransferredBytes = n
+	if err != nil {
+		return nil, fmt.Errorf("download interrupted after %d bytes (use --resume to continue): %w", offset+n, err)
+	}
+	if res.SizeBytes >= 0 && offset+n != res.SizeBytes {
+		return nil, fmt.Errorf("download incomplete: got %d of %d bytes (use --resume to continue)", offset+n, res.SizeBytes)
+	}
+	res.SizeBytes = offset + n
+
+	return finishDownload(res, h, expected)
+}
+
+func finishDownload(res *TransferResult, h hash.Hash, expected string) (*TransferResult, error) {
+	res.SHA256 = hex.EncodeToString(h.Sum(nil))
+	if expected != "" {
+		if res.SHA256 != expected {
+			return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", res.Destination, expected, res.SHA256)
+		}
+		res.Verified = true
+	}
+	return res, nil
+}
+
+// parseChecksum normalizes an expected SHA-256 given as hex with an
+// optional "sha256:" prefix
+func parseChecksum(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	sum := strings.ToLower(strings.TrimPrefix(s, "sha256:"))
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 checksum %q", s)
+	}
+	return sum, nil
+}
+
+// contentRangeSize returns the total size from a "bytes start-end/size" header
+func contentRangeSize(header string) (int64, error) {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok || total == "*" {
+		return -1, nil
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return size, nil
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashRemote returns the SHA-256 of a datastore file
+func hashRemote(ctx context.Context, ds *object.Datastore, name string) (string, error) {
+	body, _, err := ds.Download(ctx, name, nil)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	return hashReader(body)
+}
+
+// hashFile feeds the first n bytes of a local file into h
+func hashFile(h hash.Hash, name string, n int64) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	copied, err := io.Copy(h, io.LimitReader(f, n))
+	if err != nil {
+		return err
+	}
+	if copied != n {
+		return errors.New("local file changed while resuming")
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Change is a single difference between two flattened configurations.
+// Op is one of "add", "remove" or "replace", following JSON Patch; Old
+// carries the replaced or removed value for human-readable output. The
+// values are pointers so that an empty string is still emitted: JSON Patch
+// requires "value" on every add and replace, and forbids it on remove.
+type Change struct {
+	Op   string  `json:"op" yaml:"op"`
+	Path string  `json:"path" yaml:"path"`
+	Old  *string `json:"oldValue,omitempty" yaml:"oldValue,omitempty"`
+	New  *string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// VMConfig returns the current configuration of a VM
+func VMConfig(ctx context.Context, vm *object.VirtualMachine) (*types.VirtualMachineConfigInfo, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"config"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Config == nil {
+		return nil, fmt.Errorf("VM %s has no configuration (is it inaccessible?)", vm.Reference().Value)
+	}
+	return props.Config, nil
+}
+
+// SnapshotConfig returns the VM configuration captured by a snapshot
+func SnapshotConfig(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, name string) (*types.VirtualMachineConfigInfo, error) {
+	ref, err := vm.FindSnapshot(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var snap mo.VirtualMachineSnapshot
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, *ref, []string{"config"}, &snap); err != nil {
+		return nil, err
+	}
+
+	return &snap.Config, nil
+}
+
+// ConfigFields flattens a VM configuration into comparable "section/key" fields
+// covering hardware, disks, networks, other devices and advanced settings.
+// Identity values that always differ between VMs (UUIDs, disk file names) are left out.
+func ConfigFields(ctx context.Context, c *vim25.Client, cfg *types.VirtualMachineConfigInfo) (map[string]string, error) {
+	fields := make(map[string]string)
+	set := func(key string, value interface{}) {
+		fields[key] = fmt.Sprint(value)
+	}
+
+	set("hardware/cpus", cfg.Hardware.NumCPU)
+	set("hardware/coresPerSocket", cfg.Hardware.NumCoresPerSocket)
+	set("hardware/memoryMB", cfg.Hardware.MemoryMB)
+	set("hardware/version", cfg.Version)
+	set("hardware/firmware", cfg.Firmware)
+	set("hardware/guestId", cfg.GuestId)
+	set("hardware/cpuHotAdd", boolValue(cfg.CpuHotAddEnabled))
+	set("hardware/memoryHotAdd", boolValue(cfg.MemoryHotAddEnabled))
+	set("hardware/changeTrackingEnabled", boolValue(cfg.ChangeTrackingEnabled))
+
+	portgroups, err := portgroupNames(ctx, c, cfg.Hardware.Device)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+	for _, d := range devices {
+		dev := d.GetVirtualDevice()
+		label := devices.Name(d)
+		if dev.DeviceInfo != nil {
+			label = dev.DeviceInfo.GetDescription().Label
+		}
+
+		switch t := d.(type) {
+		case *types.VirtualDisk:
+			prefix := "disks/" + label + "/"
+			set(prefix+"capacity", t.CapacityInBytes)
+			set(prefix+"controller", deviceLabel(devices, t.ControllerKey))
+			if t.UnitNumber != nil {
+				set(prefix+"unit", *t.UnitNumber)
+			}
+			if b, ok := t.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+				set(prefix+"thin", boolValue(b.ThinProvisioned))
+				set(prefix+"mode", b.DiskMode)
+				set(prefix+"sharing", b.Sharing)
+			}
+		case types.BaseVirtualEthernetCard:
+			card := t.GetVirtualEthernetCard()
+			prefix := "networks/" + label + "/"
+			set(prefix+"adapter", devices.Type(d))
+			set(prefix+"network", nicNetwork(card, portgroups))
+			set(prefix+"mac", card.MacAddress)
+			if card.Connectable != nil {
+				set(prefix+"startConnected", card.Connectable.StartConnected)
+			}
+		default:
+			set("devices/"+label, devices.Type(d))
+		}
+	}
+
+	for _, o := range cfg.ExtraConfig {
+		opt := o.GetOptionValue()
+		set("extraConfig/"+opt.Key, opt.Value)
+	}
+
+	return fields, nil
+}
+
+// DiffFields compares two flattened configurations. Changes are sorted by path.
+func DiffFields(a, b map[string]string) []Change {
+	changes := []Change{}
+
+	for key, av := range a {
+		bv, ok := b[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Op: "remove", Path: patchPath(key), Old: &av})
+		case av != bv:
+			changes = append(changes, Change{Op: "replace", Path: patchPath(key), Old: &av, New: &bv})
+		}
+	}
+	for key, bv := range b {
+		if _, ok := a[key]; !ok {
+			changes = append(changes, Change{Op: "add", Path: patchPath(key), New: &bv})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// patchPath turns a "section/key/attr" field into a JSON Pointer, escaping
+// "~" and "/" inside labels and advanced setting keys
+func patchPath(field string) string {
+	section, rest, _ := strings.Cut(field, "/")
+
+	var parts []string
+	if section == "extraConfig" || section == "devices" {
+		parts = []string{section, rest}
+	} else {
+		parts = append([]string{section}, strings.SplitN(rest, "/", 2)...)
+	}
+
+	for i, p := range parts {
+		parts[i] = strings.NewReplacer("~", "~0", "/", "~1").Replace(p)
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// portgroupNames resolves the names of the distributed port groups the NICs use
+func portgroupNames(ctx context.Context, c *vim25.Client, devices []types.BaseVirtualDevice) (map[types.ManagedObjectReference]string, error) {
+	var refs []types.ManagedObjectReference
+	for _, d := range devices {
+		card, ok := d.(types.BaseVirtualEthernetCard)
+		if !ok {
+			continue
+		}
+		if b, ok := card.GetVirtualEthernetCard().Backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo); ok {
+			refs = append(refs, types.ManagedObjectReference{Type: "DistributedVirtualPortgroup", Value: b.Port.PortgroupKey})
+		}
+	}
+
+	return EntityNames(ctx, c, refs)
+}
+
+func deviceLabel(devices object.VirtualDeviceList, key int32) string {
+	d := devices.FindByKey(key)
+	if d == nil {
+		return ""
+	}
+	if info := d.GetVirtualDevice().DeviceInfo; info != nil {
+		return info.GetDescription().Label
+	}
+	return devices.Name(d)
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,287 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Disk backing types reported by DiskDetail.Backing
+const (
+	BackingFlat        = "flat"
+	BackingSparse      = "sparse"
+	BackingSeSparse    = "sesparse"
+	BackingRDMPhysical = "rdm-physical"
+	BackingRDMVirtual  = "rdm-virtual"
+	BackingVVol        = "vvol"
+	BackingPMem        = "pmem"
+	BackingOther       = "other"
+)
+
+// DiskReport describes every virtual disk of a VM, as shown by inspect disks
+type DiskReport struct {
+	VM         string       `json:"vm" yaml:"vm"`
+	Moid       string       `json:"moid" yaml:"moid"`
+	CBTEnabled bool         `json:"cbtEnabled" yaml:"cbtEnabled"`
+	Encrypted  bool         `json:"encrypted" yaml:"encrypted"`
+	Disks      []DiskDetail `json:"disks" yaml:"disks"`
+}
+
+// DiskDetail describes the backing and migration readiness of a single disk
+type DiskDetail struct {
+	Label          string `json:"label" yaml:"label"`
+	File           string `json:"file" yaml:"file"`
+	CapacityBytes  int64  `json:"capacityBytes" yaml:"capacityBytes"`
+	Controller     string `json:"controller" yaml:"controller"`
+	ControllerType string `json:"controllerType" yaml:"controllerType"`
+	BusSharing     string `json:"busSharing,omitempty" yaml:"busSharing,omitempty"`
+	UnitNumber     int32  `json:"unitNumber" yaml:"unitNumber"`
+	Backing        string `json:"backing" yaml:"backing"`
+	Provisioning   string `json:"provisioning,omitempty" yaml:"provisioning,omitempty"`
+	DiskMode       string `json:"diskMode" yaml:"diskMode"`
+	Sharing        string `json:"sharing,omitempty" yaml:"sharing,omitempty"`
+	CBT            bool   `json:"cbt" yaml:"cbt"`
+	Encrypted      bool   `json:"encrypted" yaml:"encrypted"`
+	ChainDepth     int    `json:"chainDepth" yaml:"chainDepth"`
+	// LinkedCloneBase is the first parent disk outside the VM's own
+	// directories, i.e. the base disk of a linked clone; it is not counted
+	// in ChainDepth
+	LinkedCloneBase string   `json:"linkedCloneBase,omitempty" yaml:"linkedCloneBase,omitempty"`
+	Blockers        []string `json:"blockers,omitempty" yaml:"blockers,omitempty"`
+	Warnings        []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// diskReportProps are the VM properties DiskDetails reads
+var diskReportProps = []string{"name", "config.hardware.device", "config.changeTrackingEnabled", "config.keyId", "config.files"}
+
+// DiskReportProps returns the VM properties DiskDetails needs, for callers
+// retrieving many VMs at once
+func DiskReportProps() []string {
+	return append([]string(nil), diskReportProps...)
+}
+
+// BuildDiskReport collects the disk report for a VM
+func BuildDiskReport(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) (*DiskReport, error) {
+	props, err := RetrieveVM(ctx, c, vm.Reference(), diskReportProps)
+	if err != nil {
+		return nil, err
+	}
+
+	return DiskDetails(ctx, c, props)
+}
+
+// DiskDetails builds the disk report from already retrieved VM properties
+// (see DiskReportProps)
+func DiskDetails(ctx context.Context, c *vim25.Client, vm mo.VirtualMachine) (*DiskReport, error) {
+	report := &DiskReport{
+		VM:    vm.Name,
+		Moid:  vm.Reference().Value,
+		Disks: []DiskDetail{},
+	}
+	if vm.Config == nil {
+		return nil, fmt.Errorf("VM %s has no configuration (is it inaccessible?)", vm.Name)
+	}
+
+	report.CBTEnabled = boolValue(vm.Config.ChangeTrackingEnabled)
+	report.Encrypted = vm.Config.KeyId != nil
+
+	devices := object.VirtualDeviceList(vm.Config.Hardware.Device)
+	disks := devices.SelectByType((*types.VirtualDisk)(nil))
+
+	dsTypes, err := datastoreTypes(ctx, c, disks)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshot deltas live in the VM directory or its snapshot directory
+	vmDirs := make(map[string]bool)
+	for _, name := range []string{vm.Config.Files.VmPathName, vm.Config.Files.SnapshotDirectory} {
+		if dir, ok := cleanDatastorePath(name, true); ok {
+			vmDirs[dir] = true
+		}
+	}
+
+	for _, d := range disks {
+		disk := d.(*types.VirtualDisk)
+		detail := DiskDetail{
+			Label:         deviceLabel(devices, disk.Key),
+			CapacityBytes: disk.CapacityInBytes,
+			Controller:    deviceLabel(devices, disk.ControllerKey),
+		}
+		if disk.UnitNumber != nil {
+			detail.UnitNumber = *disk.UnitNumber
+		}
+		if ctrl := devices.FindByKey(disk.ControllerKey); ctrl != nil {
+			detail.ControllerType = devices.Type(ctrl)
+			if scsi, ok := ctrl.(types.BaseVirtualSCSIController); ok {
+				detail.BusSharing = string(scsi.GetVirtualSCSIController().SharedBus)
+			}
+		}
+
+		fillBacking(&detail, disk.Backing)
+		detail.ChainDepth, detail.LinkedCloneBase = snapshotChain(diskChain(disk.Backing), vmDirs)
+		if fb, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			if ds := fb.GetVirtualDeviceFileBackingInfo().Datastore; ds != nil && dsTypes[*ds] == "VVOL" && detail.Backing == BackingFlat {
+				detail.Backing = BackingVVol
+			}
+		}
+		detail.Encrypted = detail.Encrypted || report.Encrypted
+		detail.Blockers, detail.Warnings = diskIssues(detail, report.CBTEnabled)
+
+		report.Disks = append(report.Disks, detail)
+	}
+
+	return report, nil
+}
+
+// snapshotChain counts the parents of a disk chain (as returned by diskChain)
+// that belong to the VM, stopping at the first one outside vmDirs: that one
+// is the base disk of a linked clone, not a snapshot
+func snapshotChain(chain []string, vmDirs map[string]bool) (int, string) {
+	if len(chain) < 2 {
+		return 0, ""
+	}
+	for i, name := range chain[1:] {
+		if dir, ok := cleanDatastorePath(name, true); ok && len(vmDirs) > 0 && !vmDirs[dir] {
+			return i, name
+		}
+	}
+	return len(chain) - 1, ""
+}
+
+// fillBacking records the backing type, provisioning, sharing, CBT and
+// encryption of a disk backing
+func fillBacking(d *DiskDetail, backing types.BaseVirtualDeviceBackingInfo) {
+	switch b := backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		d.File = b.FileName
+		d.Backing = BackingFlat
+		d.DiskMode = b.DiskMode
+		d.Sharing = b.Sharing
+		d.CBT = b.ChangeId != ""
+		d.Encrypted = b.KeyId != nil
+		switch {
+		case boolValue(b.ThinProvisioned):
+			d.Provisioning = "thin"
+		case boolValue(b.EagerlyScrub):
+			d.Provisioning = "thick-eager"
+		default:
+			d.Provisioning = "thick-lazy"
+		}
+	case *types.VirtualDiskSeSparseBackingInfo:
+		d.File = b.FileName
+		d.Backing = BackingSeSparse
+		d.DiskMode = b.DiskMode
+		d.Provisioning = "thin"
+		d.CBT = b.ChangeId != ""
+		d.Encrypted = b.KeyId != nil
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		d.File = b.FileName
+		d.Backing = BackingSparse
+		d.DiskMode = b.DiskMode
+		d.Provisioning = "thin"
+		d.CBT = b.ChangeId != ""
+		d.Encrypted = b.KeyId != nil
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		d.File = b.FileName
+		d.Backing = BackingRDMVirtual
+		if b.CompatibilityMode == string(types.VirtualDiskCompatibilityModePhysicalMode) {
+			d.Backing = BackingRDMPhysical
+		}
+		d.DiskMode = b.DiskMode
+		d.Sharing = b.Sharing
+		d.CBT = b.ChangeId != ""
+	case *types.VirtualDiskLocalPMemBackingInfo:
+		d.File = b.FileName
+		d.Backing = BackingPMem
+		d.DiskMode = b.DiskMode
+	default:
+		d.Backing = BackingOther
+		if fb, ok := backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			d.File = fb.GetVirtualDeviceFileBackingInfo().FileName
+		}
+	}
+}
+
+// diskIssues lists what blocks a disk from being migrated (blockers) and
+// what makes a migration slower or riskier (warnings)
+func diskIssues(d DiskDetail, vmCBT bool) ([]string, []string) {
+	var blockers, warnings []string
+
+	switch d.Backing {
+	case BackingRDMPhysical:
+		blockers = append(blockers, "physical-mode RDM")
+	case BackingRDMVirtual:
+		blockers = append(blockers, "virtual-mode RDM")
+	case BackingPMem:
+		blockers = append(blockers, "persistent memory disk")
+	case BackingOther:
+		blockers = append(blockers, "unsupported disk backing")
+	case BackingVVol:
+		warnings = append(warnings, "vVol-backed disk")
+	}
+
+	if d.Sharing == string(types.VirtualDiskSharingSharingMultiWriter) {
+		blockers = append(blockers, "multi-writer sharing")
+	}
+	if d.BusSharing != "" && d.BusSharing != string(types.VirtualSCSISharingNoSharing) {
+		blockers = append(blockers, fmt.Sprintf("SCSI bus sharing (%s)", d.BusSharing))
+	}
+	if d.Encrypted {
+		blockers = append(blockers, "encrypted")
+	}
+
+	switch types.VirtualDiskMode(d.DiskMode) {
+	case types.VirtualDiskModeIndependent_persistent, types.VirtualDiskModeIndependent_nonpersistent:
+		blockers = append(blockers, fmt.Sprintf("independent disk (%s) is excluded from snapshots", d.DiskMode))
+	}
+
+	if !vmCBT || !d.CBT {
+		warnings = append(warnings, "CBT disabled")
+	}
+	if d.ChainDepth > 0 {
+		warnings = append(warnings, fmt.Sprintf("snapshot chain depth %d", d.ChainDepth))
+	}
+	if d.LinkedCloneBase != "" {
+		warnings = append(warnings, fmt.Sprintf("linked clone of %s", d.LinkedCloneBase))
+	}
+
+	return blockers, warnings
+}
+
+// datastoreTypes returns the filesystem type (VMFS, NFS, vsan, VVOL, ...) of
+// every datastore backing the given disks
+func datastoreTypes(ctx context.Context, c *vim25.Client, disks object.VirtualDeviceList) (map[types.ManagedObjectReference]string, error) {
+	seen := make(map[types.ManagedObjectReference]bool)
+	var refs []types.ManagedObjectReference
+	for _, d := range disks {
+		fb, ok := d.GetVirtualDevice().Backing.(types.BaseVirtualDeviceFileBackingInfo)
+		if !ok {
+			continue
+		}
+		if ds := fb.GetVirtualDeviceFileBackingInfo().Datastore; ds != nil && !seen[*ds] {
+			seen[*ds] = true
+			refs = append(refs, *ds)
+		}
+	}
+
+	dsTypes := make(map[types.ManagedObjectReference]string, len(refs))
+	if len(refs) == 0 {
+		return dsTypes, nil
+	}
+
+	var stores []mo.Datastore
+	if err := property.DefaultCollector(c).Retrieve(ctx, refs, []string{"summary.type"}, &stores); err != nil {
+		return nil, err
+	}
+	for _, ds := range stores {
+		dsTypes[ds.Reference()] = ds.Summary.Type
+	}
+
+	return dsTypes, nil
+}
@@ -0,0 +1,389 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// HostReport describes an ESXi host
+type HostReport struct {
+	Name            string   `json:"name" yaml:"name"`
+	Moid            string   `json:"moid" yaml:"moid"`
+	Cluster         string   `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	OverallStatus   string   `json:"overallStatus" yaml:"overallStatus"`
+	ConnectionState string   `json:"connectionState" yaml:"connectionState"`
+	PowerState      string   `json:"powerState" yaml:"powerState"`
+	MaintenanceMode bool     `json:"maintenanceMode" yaml:"maintenanceMode"`
+	Version         string   `json:"version" yaml:"version"`
+	Build           string   `json:"build" yaml:"build"`
+	Uptime          string   `json:"uptime" yaml:"uptime"`
+	Vendor          string   `json:"vendor" yaml:"vendor"`
+	Model           string   `json:"model" yaml:"model"`
+	CPUModel        string   `json:"cpuModel" yaml:"cpuModel"`
+	CPUSockets      int16    `json:"cpuSockets" yaml:"cpuSockets"`
+	CPUCores        int16    `json:"cpuCores" yaml:"cpuCores"`
+	CPUThreads      int16    `json:"cpuThreads" yaml:"cpuThreads"`
+	CPU             Usage    `json:"cpu" yaml:"cpu"`
+	Memory          Usage    `json:"memory" yaml:"memory"`
+	VMs             []string `json:"vms" yaml:"vms"`
+	Datastores      []string `json:"datastores" yaml:"datastores"`
+	Networks        []string `json:"networks" yaml:"networks"`
+}
+
+// ClusterReport describes a compute cluster
+type ClusterReport struct {
+	Name          string   `json:"name" yaml:"name"`
+	Moid          string   `json:"moid" yaml:"moid"`
+	OverallStatus string   `json:"overallStatus" yaml:"overallStatus"`
+	DRSEnabled    bool     `json:"drsEnabled" yaml:"drsEnabled"`
+	DRSBehavior   string   `json:"drsBehavior,omitempty" yaml:"drsBehavior,omitempty"`
+	HAEnabled     bool     `json:"haEnabled" yaml:"haEnabled"`
+	HAAdmission   bool     `json:"haAdmissionControl" yaml:"haAdmissionControl"`
+	NumHosts      int32    `json:"numHosts" yaml:"numHosts"`
+	NumEffective  int32    `json:"numEffectiveHosts" yaml:"numEffectiveHosts"`
+	CPUCores      int16    `json:"cpuCores" yaml:"cpuCores"`
+	CPU           Usage    `json:"cpu" yaml:"cpu"`
+	Memory        Usage    `json:"memory" yaml:"memory"`
+	Hosts         []string `json:"hosts" yaml:"hosts"`
+	Datastores    []string `json:"datastores" yaml:"datastores"`
+	Networks      []string `json:"networks" yaml:"networks"`
+	NumVMs        int32    `json:"numVMs" yaml:"numVMs"`
+}
+
+// DatastoreReport describes a datastore
+type DatastoreReport struct {
+	Name             string   `json:"name" yaml:"name"`
+	Moid             string   `json:"moid" yaml:"moid"`
+	Type             string   `json:"type" yaml:"type"`
+	URL              string   `json:"url" yaml:"url"`
+	OverallStatus    string   `json:"overallStatus" yaml:"overallStatus"`
+	Accessible       bool     `json:"accessible" yaml:"accessible"`
+	MaintenanceMode  string   `json:"maintenanceMode,omitempty" yaml:"maintenanceMode,omitempty"`
+	CapacityBytes    int64    `json:"capacityBytes" yaml:"capacityBytes"`
+	FreeBytes        int64    `json:"freeBytes" yaml:"freeBytes"`
+	UsedBytes        int64    `json:"usedBytes" yaml:"usedBytes"`
+	UncommittedBytes int64    `json:"uncommittedBytes" yaml:"uncommittedBytes"`
+	Hosts            []string `json:"hosts" yaml:"hosts"`
+	VMs              []string `json:"vms" yaml:"vms"`
+}
+
+// NetworkReport describes a standard or distributed port group
+type NetworkReport struct {
+	Name          string   `json:"name" yaml:"name"`
+	Moid          string   `json:"moid" yaml:"moid"`
+	Type          string   `json:"type" yaml:"type"`
+	OverallStatus string   `json:"overallStatus" yaml:"overallStatus"`
+	Accessible    bool     `json:"accessible" yaml:"accessible"`
+	Switch        string   `json:"switch,omitempty" yaml:"switch,omitempty"`
+	VLAN          string   `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	NumPorts      int32    `json:"numPorts,omitempty" yaml:"numPorts,omitempty"`
+	Hosts         []string `json:"hosts" yaml:"hosts"`
+	VMs           []string `json:"vms" yaml:"vms"`
+}
+
+// ResourcePoolReport describes a resource pool
+type ResourcePoolReport struct {
+	Name          string     `json:"name" yaml:"name"`
+	Moid          string     `json:"moid" yaml:"moid"`
+	Owner         string     `json:"owner" yaml:"owner"`
+	OverallStatus string     `json:"overallStatus" yaml:"overallStatus"`
+	CPU           Allocation `json:"cpu" yaml:"cpu"`
+	Memory        Allocation `json:"memory" yaml:"memory"`
+	Children      []string   `json:"children" yaml:"children"`
+	VMs           []string   `json:"vms" yaml:"vms"`
+}
+
+// Usage is a used/total pair for a resource. CPU is in MHz, memory in bytes.
+type Usage struct {
+	Used  int64 `json:"used" yaml:"used"`
+	Total int64 `json:"total" yaml:"total"`
+}
+
+// Allocation is a resource pool's configured and actual use of a resource.
+// CPU is in MHz, memory in bytes.
+type Allocation struct {
+	Reservation    int64  `json:"reservation" yaml:"reservation"`
+	Expandable     bool   `json:"expandableReservation" yaml:"expandableReservation"`
+	Limit          int64  `json:"limit" yaml:"limit"`
+	Shares         string `json:"shares" yaml:"shares"`
+	ReservationUse int64  `json:"reservationUsed" yaml:"reservationUsed"`
+	OverallUsage   int64  `json:"overallUsage" yaml:"overallUsage"`
+}
+
+// BuildHostReport collects the report for a host
+func BuildHostReport(ctx context.Context, c *vim25.Client, host *object.HostSystem) (*HostReport, error) {
+	var h mo.HostSystem
+	props := []string{"name", "overallStatus", "summary", "vm", "datastore", "network"}
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, host.Reference(), props, &h); err != nil {
+		return nil, err
+	}
+
+	r := &HostReport{
+		Name:          h.Name,
+		Moid:          h.Reference().Value,
+		OverallStatus: string(h.OverallStatus),
+		Uptime:        (time.Duration(h.Summary.QuickStats.Uptime) * time.Second).String(),
+		CPU:           Usage{Used: int64(h.Summary.QuickStats.OverallCpuUsage)},
+		Memory:        Usage{Used: int64(h.Summary.QuickStats.OverallMemoryUsage) * 1024 * 1024},
+	}
+
+	if rt := h.Summary.Runtime; rt != nil {
+		r.ConnectionState = string(rt.ConnectionState)
+		r.PowerState = string(rt.PowerState)
+		r.MaintenanceMode = rt.InMaintenanceMode
+	}
+	if p := h.Summary.Config.Product; p != nil {
+		r.Version = p.Version
+		r.Build = p.Build
+	}
+	if hw := h.Summary.Hardware; hw != nil {
+		r.Vendor = hw.Vendor
+		r.Model = hw.Model
+		r.CPUModel = hw.CpuModel
+		r.CPUSockets = hw.NumCpuPkgs
+		r.CPUCores = hw.NumCpuCores
+		r.CPUThreads = hw.NumCpuThreads
+		r.CPU.Total = int64(hw.CpuMhz) * int64(hw.NumCpuCores)
+		r.Memory.Total = hw.MemorySize
+	}
+
+	cluster, err := HostCluster(ctx, c, h.Reference())
+	if err != nil {
+		return nil, err
+	}
+	r.Cluster = cluster
+
+	if r.VMs, err = SortedNames(ctx, c, h.Vm); err != nil {
+		return nil, err
+	}
+	if r.Datastores, err = SortedNames(ctx, c, h.Datastore); err != nil {
+		return nil, err
+	}
+	if r.Networks, err = SortedNames(ctx, c, h.Network); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// BuildClusterReport collects the report for a cluster
+func BuildClusterReport(ctx context.Context, c *vim25.Client, cluster *object.ClusterComputeResource) (*ClusterReport, error) {
+	var cl mo.ClusterComputeResource
+	props := []string{"name", "overallStatus", "summary", "configurationEx", "host", "datastore", "network"}
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, cluster.Reference(), props, &cl); err != nil {
+		return nil, err
+	}
+
+	r := &ClusterReport{
+		Name:          cl.Name,
+		Moid:          cl.Reference().Value,
+		OverallStatus: string(cl.OverallStatus),
+	}
+
+	if s, ok := cl.Summary.(*types.ClusterComputeResourceSummary); ok {
+		r.NumHosts = s.NumHosts
+		r.NumEffective = s.NumEffectiveHosts
+		r.CPUCores = s.NumCpuCores
+		r.CPU.Total = int64(s.TotalCpu)
+		r.Memory.Total = s.TotalMemory
+		if u := s.UsageSummary; u != nil {
+			r.CPU.Used = int64(u.CpuDemandMhz)
+			r.Memory.Used = int64(u.MemDemandMB) * 1024 * 1024
+			r.NumVMs = u.TotalVmCount
+		}
+	}
+
+	if cfg, ok := cl.ConfigurationEx.(*types.ClusterConfigInfoEx); ok {
+		r.DRSEnabled = cfg.DrsConfig.Enabled != nil && *cfg.DrsConfig.Enabled
+		r.DRSBehavior = string(cfg.DrsConfig.DefaultVmBehavior)
+		r.HAEnabled = cfg.DasConfig.Enabled != nil && *cfg.DasConfig.Enabled
+		r.HAAdmission = cfg.DasConfig.AdmissionControlEnabled != nil && *cfg.DasConfig.AdmissionControlEnabled
+	}
+
+	var err error
+	if r.Hosts, err = SortedNames(ctx, c, cl.Host); err != nil {
+		return nil, err
+	}
+	if r.Datastores, err = SortedNames(ctx, c, cl.Datastore); err != nil {
+		return nil, err
+	}
+	if r.Networks, err = SortedNames(ctx, c, cl.Network); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// BuildDatastoreReport collects the report for a datastore
+func BuildDatastoreReport(ctx context.Context, c *vim25.Client, ds *object.Datastore) (*DatastoreReport, error) {
+	var d mo.Datastore
+	props := []string{"name", "overallStatus", "summary", "host", "vm"}
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, ds.Reference(), props, &d); err != nil {
+		return nil, err
+	}
+
+	r := &DatastoreReport{
+		Name:             d.Name,
+		Moid:             d.Reference().Value,
+		Type:             d.Summary.Type,
+		URL:              d.Summary.Url,
+		OverallStatus:    string(d.OverallStatus),
+		Accessible:       d.Summary.Accessible,
+		MaintenanceMode:  d.Summary.MaintenanceMode,
+		CapacityBytes:    d.Summary.Capacity,
+		FreeBytes:        d.Summary.FreeSpace,
+		UsedBytes:        d.Summary.Capacity - d.Summary.FreeSpace,
+		UncommittedBytes: d.Summary.Uncommitted,
+	}
+
+	hosts := make([]types.ManagedObjectReference, 0, len(d.Host))
+	for _, m := range d.Host {
+		hosts = append(hosts, m.Key)
+	}
+
+	var err error
+	if r.Hosts, err = SortedNames(ctx, c, hosts); err != nil {
+		return nil, err
+	}
+	if r.VMs, err = SortedNames(ctx, c, d.Vm); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// BuildNetworkReport collects the report for a standard or distributed port group
+func BuildNetworkReport(ctx context.Context, c *vim25.Client, ref types.ManagedObjectReference) (*NetworkReport, error) {
+	pc := property.DefaultCollector(c)
+
+	var n mo.Network
+	if err := pc.RetrieveOne(ctx, ref, []string{"name", "overallStatus", "summary", "host", "vm"}, &n); err != nil {
+		return nil, err
+	}
+
+	r := &NetworkReport{
+		Name:          n.Name,
+		Moid:          ref.Value,
+		Type:          ref.Type,
+		OverallStatus: string(n.OverallStatus),
+	}
+	if n.Summary != nil {
+		r.Accessible = n.Summary.GetNetworkSummary().Accessible
+	}
+
+	if ref.Type == "DistributedVirtualPortgroup" {
+		var pg mo.DistributedVirtualPortgroup
+		if err := pc.RetrieveOne(ctx, ref, []string{"config"}, &pg); err != nil {
+			return nil, err
+		}
+
+		r.NumPorts = pg.Config.NumPorts
+		r.VLAN = PortgroupVLAN(pg.Config)
+		if pg.Config.DistributedVirtualSwitch != nil {
+			names, err := EntityNames(ctx, c, []types.ManagedObjectReference{*pg.Config.DistributedVirtualSwitch})
+			if err != nil {
+				return nil, err
+			}
+			r.Switch = names[*pg.Config.DistributedVirtualSwitch]
+		}
+	}
+
+	var err error
+	if r.Hosts, err = SortedNames(ctx, c, n.Host); err != nil {
+		return nil, err
+	}
+	if r.VMs, err = SortedNames(ctx, c, n.Vm); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// PortgroupVLAN describes the VLAN configuration of a distributed port group
+func PortgroupVLAN(cfg types.DVPortgroupConfigInfo) string {
+	setting, ok := cfg.DefaultPortConfig.(*types.VMwareDVSPortSetting)
+	if !ok || setting.Vlan == nil {
+		return ""
+	}
+
+	switch v := setting.Vlan.(type) {
+	case *types.VmwareDistributedVirtualSwitchVlanIdSpec:
+		return fmt.Sprintf("%d", v.VlanId)
+	case *types.VmwareDistributedVirtualSwitchTrunkVlanSpec:
+		s := "trunk"
+		for i, r := range v.VlanId {
+			sep := ","
+			if i == 0 {
+				sep = " "
+			}
+			s += fmt.Sprintf("%s%d-%d", sep, r.Start, r.End)
+		}
+		return s
+	case *types.VmwareDistributedVirtualSwitchPvlanSpec:
+		return fmt.Sprintf("pvlan %d", v.PvlanId)
+	default:
+		return ""
+	}
+}
+
+// BuildResourcePoolReport collects the report for a resource pool
+func BuildResourcePoolReport(ctx context.Context, c *vim25.Client, pool *object.ResourcePool) (*ResourcePoolReport, error) {
+	var p mo.ResourcePool
+	props := []string{"name", "overallStatus", "config", "runtime", "owner", "resourcePool", "vm"}
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, pool.Reference(), props, &p); err != nil {
+		return nil, err
+	}
+
+	r := &ResourcePoolReport{
+		Name:          p.Name,
+		Moid:          p.Reference().Value,
+		OverallStatus: string(p.OverallStatus),
+		CPU:           allocation(p.Config.CpuAllocation, p.Runtime.Cpu, 1),
+		Memory:        allocation(p.Config.MemoryAllocation, p.Runtime.Memory, 1024*1024),
+	}
+
+	owner, err := EntityNames(ctx, c, []types.ManagedObjectReference{p.Owner})
+	if err != nil {
+		return nil, err
+	}
+	r.Owner = owner[p.Owner]
+
+	if r.Children, err = SortedNames(ctx, c, p.ResourcePool); err != nil {
+		return nil, err
+	}
+	if r.VMs, err = SortedNames(ctx, c, p.Vm); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// allocation combines a pool's configured allocation with its runtime usage.
+// The configured values are scaled by unit to match the runtime values.
+func allocation(cfg types.ResourceAllocationInfo, usage types.ResourcePoolResourceUsage, unit int64) Allocation {
+	a := Allocation{
+		Limit:          -1,
+		ReservationUse: usage.ReservationUsed,
+		OverallUsage:   usage.OverallUsage,
+	}
+	if cfg.Reservation != nil {
+		a.Reservation = *cfg.Reservation * unit
+	}
+	if cfg.ExpandableReservation != nil {
+		a.Expandable = *cfg.ExpandableReservation
+	}
+	if cfg.Limit != nil && *cfg.Limit >= 0 {
+		a.Limit = *cfg.Limit * unit
+	}
+	if cfg.Shares != nil {
+		a.Shares = fmt.Sprintf("%s (%d)", cfg.Shares.Level, cfg.Shares.Shares)
+	}
+	return a
+}
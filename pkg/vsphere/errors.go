@@ -0,0 +1,39 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// transientFaults are vSphere faults that usually clear up on their own,
+// typically because another task holds a lock on the same object
+var transientFaults = []types.BaseMethodFault{
+	&types.TaskInProgress{},
+	&types.ConcurrentAccess{},
+	&types.HostCommunication{},
+	&types.Timedout{},
+}
+
+// IsTransient reports whether err is likely to succeed if the operation is retried
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	for _, f := range transientFaults {
+		if fault.Is(err, f) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+)
+
+// GuestReport is what VMware Tools reports about the guest, as shown by guest info
+type GuestReport struct {
+	VM            string       `json:"vm" yaml:"vm"`
+	PowerState    string       `json:"powerState" yaml:"powerState"`
+	Tools         GuestTools   `json:"tools" yaml:"tools"`
+	GuestFamily   string       `json:"guestFamily,omitempty" yaml:"guestFamily,omitempty"`
+	GuestFullName string       `json:"guestFullName,omitempty" yaml:"guestFullName,omitempty"`
+	GuestID       string       `json:"guestId,omitempty" yaml:"guestId,omitempty"`
+	Hostname      string       `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	IPAddress     string       `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
+	NICs          []GuestNIC   `json:"nics" yaml:"nics"`
+	Disks         []GuestMount `json:"disks" yaml:"disks"`
+}
+
+// GuestTools describes the VMware Tools installation
+type GuestTools struct {
+	RunningStatus string `json:"runningStatus" yaml:"runningStatus"`
+	Version       string `json:"version,omitempty" yaml:"version,omitempty"`
+	VersionStatus string `json:"versionStatus,omitempty" yaml:"versionStatus,omitempty"`
+}
+
+// GuestNIC is a network interface as seen by the guest
+type GuestNIC struct {
+	Network   string   `json:"network,omitempty" yaml:"network,omitempty"`
+	MAC       string   `json:"mac" yaml:"mac"`
+	Connected bool     `json:"connected" yaml:"connected"`
+	IPs       []string `json:"ips" yaml:"ips"`
+}
+
+// GuestMount is a mounted filesystem as seen by the guest
+type GuestMount struct {
+	Path           string `json:"path" yaml:"path"`
+	FilesystemType string `json:"filesystemType,omitempty" yaml:"filesystemType,omitempty"`
+	CapacityBytes  int64  `json:"capacityBytes" yaml:"capacityBytes"`
+	FreeBytes      int64  `json:"freeBytes" yaml:"freeBytes"`
+}
+
+// BuildGuestReport collects the guest information reported by VMware Tools
+func BuildGuestReport(ctx context.Context, vm *object.VirtualMachine) (*GuestReport, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"name", "runtime.powerState", "guest"}, &props); err != nil {
+		return nil, err
+	}
+
+	r := &GuestReport{
+		VM:         props.Name,
+		PowerState: string(props.Runtime.PowerState),
+		NICs:       []GuestNIC{},
+		Disks:      []GuestMount{},
+	}
+
+	g := props.Guest
+	if g == nil {
+		return r, nil
+	}
+
+	r.Tools = GuestTools{
+		RunningStatus: g.ToolsRunningStatus,
+		Version:       g.ToolsVersion,
+		VersionStatus: g.ToolsVersionStatus2,
+	}
+	r.GuestFamily = g.GuestFamily
+	r.GuestFullName = g.GuestFullName
+	r.GuestID = g.GuestId
+	r.Hostname = g.HostName
+	r.IPAddress = g.IpAddress
+
+	for _, n := range g.Net {
+		nic := GuestNIC{
+			Network:   n.Network,
+			MAC:       n.MacAddress,
+			Connected: n.Connected,
+			IPs:       []string{},
+		}
+		if n.IpConfig != nil {
+			for _, ip := range n.IpConfig.IpAddress {
+				nic.IPs = append(nic.IPs, fmt.Sprintf("%s/%d", ip.IpAddress, ip.PrefixLength))
+			}
+		} else {
+			nic.IPs = append(nic.IPs, n.IpAddress...)
+		}
+		r.NICs = append(r.NICs, nic)
+	}
+
+	for _, d := range g.Disk {
+		r.Disks = append(r.Disks, GuestMount{
+			Path:           d.DiskPath,
+			FilesystemType: d.FilesystemType,
+			CapacityBytes:  d.Capacity,
+			FreeBytes:      d.FreeSpace,
+		})
+	}
+
+	return r, nil
+}
@@ -0,0 +1,289 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/guest/toolbox"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// GuestProcess is a process running (or recently exited) in the guest
+type GuestProcess struct {
+	PID       int64     `json:"pid" yaml:"pid"`
+	Name      string    `json:"name" yaml:"name"`
+	Owner     string    `json:"owner" yaml:"owner"`
+	CmdLine   string    `json:"cmdLine" yaml:"cmdLine"`
+	StartTime time.Time `json:"startTime" yaml:"startTime"`
+	Exited    bool      `json:"exited" yaml:"exited"`
+	ExitCode  int32     `json:"exitCode,omitempty" yaml:"exitCode,omitempty"`
+}
+
+// NewGuestClient opens a Guest Operations client for a VM, failing early
+// if VMware Tools is not running
+func NewGuestClient(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, username, password string) (*toolbox.Client, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"guest.toolsRunningStatus", "guest.guestOperationsReady"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Guest == nil || props.Guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return nil, fmt.Errorf("VMware Tools is not running in the guest")
+	}
+	if props.Guest.GuestOperationsReady != nil && !*props.Guest.GuestOperationsReady {
+		return nil, fmt.Errorf("guest operations are not ready yet")
+	}
+
+	auth := &types.NamePasswordAuthentication{Username: username, Password: password}
+	return toolbox.NewClient(ctx, c, vm.Reference(), auth)
+}
+
+// guestExecPollInterval is how often GuestExec checks on the guest program
+// and copies its new output
+const guestExecPollInterval = time.Second
+
+// guestOutput is a standard stream of a guest program, redirected to a
+// temporary file in the guest
+type guestOutput struct {
+	w      io.Writer
+	path   string
+	copied int64
+}
+
+// GuestExec runs a program in the guest and streams its output to stdout and
+// stderr while it runs. It returns the program's exit code. Programs without
+// an absolute path run through /bin/bash -c on Linux, and through cmd.exe on Windows.
+//
+// The Guest Operations API has no pipes, so the output is redirected to
+// temporary files in the guest, and every poll downloads what was appended
+// since the last one. If ctx is canceled, the program is terminated.
+func GuestExec(ctx context.Context, tc *toolbox.Client, args []string, stdout, stderr io.Writer) (int, error) {
+	// Clean up even when ctx is canceled
+	cleanup := context.WithoutCancel(ctx)
+
+	outputs := []*guestOutput{{w: stdout}, {w: stderr}}
+	for _, out := range outputs {
+		p, err := tc.FileManager.CreateTemporaryFile(ctx, tc.Authentication, "vcli-", "", "")
+		if err != nil {
+			return -1, err
+		}
+		defer tc.FileManager.DeleteFile(cleanup, tc.Authentication, p)
+		out.path = p
+	}
+
+	spec := guestProgramSpec(tc.GuestFamily, args[0], args[1:], outputs[0].path, outputs[1].path)
+	pid, err := tc.ProcessManager.StartProgram(ctx, tc.Authentication, &spec)
+	if err != nil {
+		return -1, err
+	}
+
+	exited := false
+	defer func() {
+		// Do not leave the program running when the caller gives up
+		if !exited && ctx.Err() != nil {
+			_ = tc.ProcessManager.TerminateProcess(cleanup, tc.Authentication, pid)
+		}
+	}()
+
+	ticker := time.NewTicker(guestExecPollInterval)
+	defer ticker.Stop()
+
+	for {
+		procs, err := tc.ProcessManager.ListProcesses(ctx, tc.Authentication, []int64{pid})
+		if err != nil {
+			return -1, err
+		}
+		if len(procs) == 0 {
+			return -1, fmt.Errorf("guest process %d is gone", pid)
+		}
+		// Copy after checking for exit, so the last pass sees all output
+		exited = procs[0].EndTime != nil
+		for _, out := range outputs {
+			if err := out.copyNew(ctx, tc); err != nil {
+				return -1, err
+			}
+		}
+		if exited {
+			return int(procs[0].ExitCode), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// copyNew writes the bytes appended to the output file since the last call.
+// It asks for them with a range request, and skips the copied prefix itself
+// if the guest sends the whole file anyway.
+func (o *guestOutput) copyNew(ctx context.Context, tc *toolbox.Client) error {
+	info, err := tc.FileManager.InitiateFileTransferFromGuest(ctx, tc.Authentication, o.path)
+	if err != nil {
+		return err
+	}
+	if info.Size <= o.copied {
+		return nil
+	}
+
+	u, err := tc.FileManager.TransferURL(ctx, info.Url)
+	if err != nil {
+		return err
+	}
+	param := soap.DefaultDownload
+	param.Close = true
+	param.Headers = map[string]string{"Range": fmt.Sprintf("bytes=%d-", o.copied)}
+
+	resp, err := tc.ProcessManager.Client().DownloadRequest(ctx, u, &param)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		if _, err := io.CopyN(io.Discard, resp.Body, o.copied); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("download(%s): %s", o.path, resp.Status)
+	}
+
+	n, err := io.Copy(o.w, io.LimitReader(resp.Body, info.Size-o.copied))
+	o.copied += n
+	return err
+}
+
+// guestProgramSpec builds the spec that runs path with args and redirects
+// its output to the stdout and stderr files. Windows programs run through
+// cmd.exe, which does the redirection; on Linux vmware-tools runs the
+// program through a shell, and programs without an absolute path through
+// bash -c. Every word is quoted for the shell that parses it.
+func guestProgramSpec(family types.VirtualMachineGuestOsFamily, path string, args []string, stdout, stderr string) types.GuestProgramSpec {
+	quote := posixQuote
+	if family == types.VirtualMachineGuestOsFamilyWindowsGuest {
+		quote = windowsQuote
+	}
+
+	words := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{path}, args...) {
+		words = append(words, quote(a))
+	}
+	redirects := " 1> " + quote(stdout) + " 2> " + quote(stderr)
+
+	switch {
+	case family == types.VirtualMachineGuestOsFamilyWindowsGuest:
+		// cmd.exe drops the outer quotes of /c "...", leaving the words as quoted
+		return types.GuestProgramSpec{
+			ProgramPath: `c:\Windows\System32\cmd.exe`,
+			Arguments:   `/c "` + strings.Join(words, " ") + redirects + `"`,
+		}
+	case !strings.Contains(path, "/"):
+		return types.GuestProgramSpec{
+			ProgramPath: "/bin/bash",
+			Arguments:   "-c " + posixQuote(strings.Join(words, " ")+redirects),
+		}
+	}
+	return types.GuestProgramSpec{
+		ProgramPath: path,
+		Arguments:   strings.Join(words[1:], " ") + redirects,
+	}
+}
+
+// posixQuote quotes s as a single word for a POSIX shell
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// windowsQuote quotes s as a single argument for the Windows command line
+// parser, and so that cmd.exe does not interpret its metacharacters
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"&|<>^()") {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			slashes++
+		case '"':
+			// Backslashes before a quote are escapes, so double them and
+			// escape the quote
+			b.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+		b.WriteByte(s[i])
+	}
+	// Likewise for the backslashes before the closing quote
+	b.WriteString(strings.Repeat(`\`, slashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// GuestUpload copies src to path dst in the guest
+func GuestUpload(ctx context.Context, tc *toolbox.Client, src io.Reader, size int64, dst string, overwrite bool) error {
+	var attr types.BaseGuestFileAttributes = new(types.GuestPosixFileAttributes)
+	if tc.GuestFamily == types.VirtualMachineGuestOsFamilyWindowsGuest {
+		attr = new(types.GuestWindowsFileAttributes)
+	}
+
+	p := soap.DefaultUpload
+	p.ContentLength = size
+	return tc.Upload(ctx, src, dst, p, attr, overwrite)
+}
+
+// GuestDownload copies the guest file src to dst and returns the bytes written
+func GuestDownload(ctx context.Context, tc *toolbox.Client, src string, dst io.Writer) (int64, error) {
+	f, _, err := tc.Download(ctx, src)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(dst, f)
+}
+
+// GuestProcesses lists the processes in the guest, or only pids if given
+func GuestProcesses(ctx context.Context, tc *toolbox.Client, pids []int64) ([]GuestProcess, error) {
+	infos, err := tc.ProcessManager.ListProcesses(ctx, tc.Authentication, pids)
+	if err != nil {
+		return nil, err
+	}
+
+	procs := make([]GuestProcess, 0, len(infos))
+	for _, p := range infos {
+		gp := GuestProcess{
+			PID:       p.Pid,
+			Name:      p.Name,
+			Owner:     p.Owner,
+			CmdLine:   p.CmdLine,
+			StartTime: p.StartTime,
+			Exited:    p.EndTime != nil,
+		}
+		if gp.Exited {
+			gp.ExitCode = p.ExitCode
+		}
+		procs = append(procs, gp)
+	}
+
+	return procs, nil
+}
+
+// GuestKill terminates a process in the guest
+func GuestKill(ctx context.Context, tc *toolbox.Client, pid int64) error {
+	return tc.ProcessManager.TerminateProcess(ctx, tc.Authentication, pid)
+}
@@ -0,0 +1,98 @@
+package vsphere
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestGuestProgramSpec(t *testing.T) {
+	linux := types.VirtualMachineGuestOsFamilyLinuxGuest
+	windows := types.VirtualMachineGuestOsFamilyWindowsGuest
+
+	tests := []struct {
+		name     string
+		family   types.VirtualMachineGuestOsFamily
+		path     string
+		args     []string
+		wantPath string
+		wantArgs string
+	}{
+		{
+			name:     "linux absolute path",
+			family:   linux,
+			path:     "/usr/bin/systemctl",
+			args:     []string{"is-active", "sshd"},
+			wantPath: "/usr/bin/systemctl",
+			wantArgs: `'is-active' 'sshd' 1> '/tmp/out' 2> '/tmp/err'`,
+		},
+		{
+			name:     "linux absolute path with spaces",
+			family:   linux,
+			path:     "/bin/echo",
+			args:     []string{"hello world", ""},
+			wantPath: "/bin/echo",
+			wantArgs: `'hello world' '' 1> '/tmp/out' 2> '/tmp/err'`,
+		},
+		{
+			name:     "linux absolute path with quote",
+			family:   linux,
+			path:     "/bin/echo",
+			args:     []string{"it's; rm -rf /"},
+			wantPath: "/bin/echo",
+			wantArgs: `'it'\''s; rm -rf /' 1> '/tmp/out' 2> '/tmp/err'`,
+		},
+		{
+			name:     "linux through bash",
+			family:   linux,
+			path:     "uname",
+			args:     []string{"-a"},
+			wantPath: "/bin/bash",
+			wantArgs: `-c ''\''uname'\'' '\''-a'\'' 1> '\''/tmp/out'\'' 2> '\''/tmp/err'\'''`,
+		},
+		{
+			name:     "linux through bash with quote",
+			family:   linux,
+			path:     "echo",
+			args:     []string{"a'b"},
+			wantPath: "/bin/bash",
+			wantArgs: `-c ''\''echo'\'' '\''a'\''\'\'''\''b'\'' 1> '\''/tmp/out'\'' 2> '\''/tmp/err'\'''`,
+		},
+		{
+			name:     "windows plain",
+			family:   windows,
+			path:     "ipconfig",
+			args:     []string{"/all"},
+			wantPath: `c:\Windows\System32\cmd.exe`,
+			wantArgs: `/c "ipconfig /all 1> /tmp/out 2> /tmp/err"`,
+		},
+		{
+			name:     "windows spaces and metacharacters",
+			family:   windows,
+			path:     `C:\Program Files\app.exe`,
+			args:     []string{"a b", "x&y", ""},
+			wantPath: `c:\Windows\System32\cmd.exe`,
+			wantArgs: `/c ""C:\Program Files\app.exe" "a b" "x&y" "" 1> /tmp/out 2> /tmp/err"`,
+		},
+		{
+			name:     "windows quotes and backslashes",
+			family:   windows,
+			path:     "echo",
+			args:     []string{`say "hi"`, `dir\`, `a\"b`, `C:\My Dir\`},
+			wantPath: `c:\Windows\System32\cmd.exe`,
+			wantArgs: `/c "echo "say \"hi\"" dir\ "a\\\"b" "C:\My Dir\\" 1> /tmp/out 2> /tmp/err"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := guestProgramSpec(tt.family, tt.path, tt.args, "/tmp/out", "/tmp/err")
+			if spec.ProgramPath != tt.wantPath {
+				t.Errorf("ProgramPath = %q, want %q", spec.ProgramPath, tt.wantPath)
+			}
+			if spec.Arguments != tt.wantArgs {
+				t.Errorf("Arguments = %q, want %q", spec.Arguments, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// HostSummary is an ESXi host as shown by host list
+type HostSummary struct {
+	Name            string `json:"name" yaml:"name"`
+	Moid            string `json:"moid" yaml:"moid"`
+	Cluster         string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	ConnectionState string `json:"connectionState" yaml:"connectionState"`
+	PowerState      string `json:"powerState" yaml:"powerState"`
+	MaintenanceMode bool   `json:"maintenanceMode" yaml:"maintenanceMode"`
+	Version         string `json:"version" yaml:"version"`
+	Build           string `json:"build" yaml:"build"`
+	CPU             Usage  `json:"cpu" yaml:"cpu"`
+	Memory          Usage  `json:"memory" yaml:"memory"`
+	NumVMs          int    `json:"numVMs" yaml:"numVMs"`
+}
+
+// ClusterSummary is a compute cluster as shown by cluster list
+type ClusterSummary struct {
+	Name         string `json:"name" yaml:"name"`
+	Moid         string `json:"moid" yaml:"moid"`
+	DRSEnabled   bool   `json:"drsEnabled" yaml:"drsEnabled"`
+	DRSBehavior  string `json:"drsBehavior,omitempty" yaml:"drsBehavior,omitempty"`
+	HAEnabled    bool   `json:"haEnabled" yaml:"haEnabled"`
+	HAAdmission  bool   `json:"haAdmissionControl" yaml:"haAdmissionControl"`
+	NumHosts     int32  `json:"numHosts" yaml:"numHosts"`
+	NumEffective int32  `json:"numEffectiveHosts" yaml:"numEffectiveHosts"`
+	CPUCores     int16  `json:"cpuCores" yaml:"cpuCores"`
+	CPU          Usage  `json:"cpu" yaml:"cpu"`
+	Memory       Usage  `json:"memory" yaml:"memory"`
+	NumVMs       int32  `json:"numVMs" yaml:"numVMs"`
+}
+
+// ListHosts returns the summary of every host under root, sorted by name
+func ListHosts(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference) ([]HostSummary, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"HostSystem"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var hosts []mo.HostSystem
+	if err := v.Retrieve(ctx, []string{"HostSystem"}, []string{"name", "parent", "summary", "vm"}, &hosts); err != nil {
+		return nil, err
+	}
+
+	var clusters []types.ManagedObjectReference
+	for _, h := range hosts {
+		if h.Parent != nil && h.Parent.Type == "ClusterComputeResource" {
+			clusters = append(clusters, *h.Parent)
+		}
+	}
+	clusterNames, err := EntityNames(ctx, c, dedupeRefs(clusters))
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]HostSummary, 0, len(hosts))
+	for _, h := range hosts {
+		s := HostSummary{
+			Name:   h.Name,
+			Moid:   h.Reference().Value,
+			CPU:    Usage{Used: int64(h.Summary.QuickStats.OverallCpuUsage)},
+			Memory: Usage{Used: int64(h.Summary.QuickStats.OverallMemoryUsage) * 1024 * 1024},
+			NumVMs: len(h.Vm),
+		}
+		if h.Parent != nil {
+			s.Cluster = clusterNames[*h.Parent]
+		}
+		if rt := h.Summary.Runtime; rt != nil {
+			s.ConnectionState = string(rt.ConnectionState)
+			s.PowerState = string(rt.PowerState)
+			s.MaintenanceMode = rt.InMaintenanceMode
+		}
+		if p := h.Summary.Config.Product; p != nil {
+			s.Version = p.Version
+			s.Build = p.Build
+		}
+		if hw := h.Summary.Hardware; hw != nil {
+			s.CPU.Total = int64(hw.CpuMhz) * int64(hw.NumCpuCores)
+			s.Memory.Total = hw.MemorySize
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	return summaries, nil
+}
+
+// ListClusters returns the summary of every cluster under root, sorted by name
+func ListClusters(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference) ([]ClusterSummary, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"ClusterComputeResource"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var clusters []mo.ClusterComputeResource
+	if err := v.Retrieve(ctx, []string{"ClusterComputeResource"}, []string{"name", "summary", "configurationEx"}, &clusters); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]ClusterSummary, 0, len(clusters))
+	for _, cl := range clusters {
+		s := ClusterSummary{
+			Name: cl.Name,
+			Moid: cl.Reference().Value,
+		}
+		if cs, ok := cl.Summary.(*types.ClusterComputeResourceSummary); ok {
+			s.NumHosts = cs.NumHosts
+			s.NumEffective = cs.NumEffectiveHosts
+			s.CPUCores = cs.NumCpuCores
+			s.CPU.Total = int64(cs.TotalCpu)
+			s.Memory.Total = cs.TotalMemory
+			if u := cs.UsageSummary; u != nil {
+				s.CPU.Used = int64(u.CpuDemandMhz)
+				s.Memory.Used = int64(u.MemDemandMB) * 1024 * 1024
+				s.NumVMs = u.TotalVmCount
+			}
+		}
+		if cfg, ok := cl.ConfigurationEx.(*types.ClusterConfigInfoEx); ok {
+			s.DRSEnabled = boolValue(cfg.DrsConfig.Enabled)
+			s.DRSBehavior = string(cfg.DrsConfig.DefaultVmBehavior)
+			s.HAEnabled = boolValue(cfg.DasConfig.Enabled)
+			s.HAAdmission = boolValue(cfg.DasConfig.AdmissionControlEnabled)
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	return summaries, nil
+}
+
+// MaintenanceOptions controls EnterMaintenance
+type MaintenanceOptions struct {
+	// Timeout fails the task, and leaves the host out of maintenance mode,
+	// when the host has not been evacuated in time; zero waits forever
+	Timeout time.Duration
+	// EvacuatePoweredOff also moves powered-off and suspended VMs off the
+	// host (DRS clusters on vCenter only)
+	EvacuatePoweredOff bool
+	// PollInterval is how often the VMs left on the host are reported
+	PollInterval time.Duration
+}
+
+// HostMaintenanceState returns the host name and whether it is in
+// maintenance mode
+func HostMaintenanceState(ctx context.Context, c *vim25.Client, host *object.HostSystem) (string, bool, error) {
+	var h mo.HostSystem
+	if err := property.DefaultCollector(c).RetrieveOne(ctx, host.Reference(), []string{"name", "runtime.inMaintenanceMode"}, &h); err != nil {
+		return "", false, err
+	}
+	return h.Name, h.Runtime.InMaintenanceMode, nil
+}
+
+// EvacuationBlocker explains why powered-on VMs will not leave the host on
+// their own, or returns "" when the host's cluster has DRS fully automated
+func EvacuationBlocker(ctx context.Context, c *vim25.Client, host *object.HostSystem) (string, error) {
+	pc := property.DefaultCollector(c)
+
+	var h mo.HostSystem
+	if err := pc.RetrieveOne(ctx, host.Reference(), []string{"parent"}, &h); err != nil {
+		return "", err
+	}
+	if h.Parent == nil || h.Parent.Type != "ClusterComputeResource" {
+		return "the host is not in a cluster", nil
+	}
+
+	var cl mo.ClusterComputeResource
+	if err := pc.RetrieveOne(ctx, *h.Parent, []string{"configurationEx"}, &cl); err != nil {
+		return "", err
+	}
+	cfg, ok := cl.ConfigurationEx.(*types.ClusterConfigInfoEx)
+	switch {
+	case !ok || !boolValue(cfg.DrsConfig.Enabled):
+		return "DRS is disabled on the cluster", nil
+	case cfg.DrsConfig.DefaultVmBehavior != "" && cfg.DrsConfig.DefaultVmBehavior != types.DrsBehaviorFullyAutomated:
+		return fmt.Sprintf("DRS is %s, not fullyAutomated", cfg.DrsConfig.DefaultVmBehavior), nil
+	}
+	return "", nil
+}
+
+// EnterMaintenance puts the host into maintenance mode and waits until it
+// has been evacuated. While waiting, remaining is called with the names of
+// the powered-on VMs still on the host whenever that list changes.
+func EnterMaintenance(ctx context.Context, c *vim25.Client, host *object.HostSystem, opts MaintenanceOptions, remaining func([]string)) error {
+	task, err := host.EnterMaintenanceMode(ctx, int32(opts.Timeout.Seconds()), opts.EvacuatePoweredOff, nil)
+	if err != nil {
+		return err
+	}
+
+	interval := opts.PollInterval
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := task.WaitForResult(ctx)
+		done <- err
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []string
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			if remaining == nil {
+				continue
+			}
+			vms, err := poweredOnVMs(ctx, c, host)
+			if err != nil {
+				// Reporting is best effort; the task decides the outcome
+				continue
+			}
+			if !slices.Equal(vms, last) {
+				remaining(vms)
+				last = vms
+			}
+		}
+	}
+}
+
+// ExitMaintenance takes the host out of maintenance mode
+func ExitMaintenance(ctx context.Context, host *object.HostSystem, timeout time.Duration) error {
+	task, err := host.ExitMaintenanceMode(ctx, int32(timeout.Seconds()))
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// poweredOnVMs returns the sorted names of the powered-on VMs on a host
+func poweredOnVMs(ctx context.Context, c *vim25.Client, host *object.HostSystem) ([]string, error) {
+	pc := property.DefaultCollector(c)
+
+	var h mo.HostSystem
+	if err := pc.RetrieveOne(ctx, host.Reference(), []string{"vm"}, &h); err != nil {
+		return nil, err
+	}
+	if len(h.Vm) == 0 {
+		return nil, nil
+	}
+
+	var vms []mo.VirtualMachine
+	if err := pc.Retrieve(ctx, h.Vm, []string{"name", "runtime.powerState"}, &vms); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, vm := range vms {
+		if vm.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn {
+			names = append(names, vm.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,113 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// InstantCloneOptions controls how an instant clone is created
+type InstantCloneOptions struct {
+	// RefreshMAC gives every NIC of the clone a newly generated MAC address
+	RefreshMAC bool
+	// GuestInfo sets guestinfo.<key> variables the guest can read to
+	// reconfigure its network after the clone resumes
+	GuestInfo map[string]string
+}
+
+// CheckInstantCloneSupport returns an error if the server cannot create instant clones.
+// Instant Clone is only available through vCenter 6.7 or later.
+func CheckInstantCloneSupport(c *vim25.Client) error {
+	about := c.ServiceContent.About
+	if about.ApiType != "VirtualCenter" {
+		return fmt.Errorf("instant clone requires vCenter, but %s is %s", c.URL().Host, about.FullName)
+	}
+	if !apiVersionAtLeast(about.ApiVersion, 6, 7) {
+		return fmt.Errorf("instant clone requires vCenter 6.7 or later, server API version is %s", about.ApiVersion)
+	}
+	return nil
+}
+
+// InstantClone creates a running instant clone of a powered-on VM in the
+// source VM's folder
+func InstantClone(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, name string, opts InstantCloneOptions) (*object.VirtualMachine, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"parent", "runtime.powerState"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		return nil, fmt.Errorf("instant clone requires a powered-on source VM, %s is %s", vm.Reference().Value, props.Runtime.PowerState)
+	}
+
+	spec := types.VirtualMachineInstantCloneSpec{
+		Name:     name,
+		Location: types.VirtualMachineRelocateSpec{Folder: props.Parent},
+	}
+
+	for key, value := range opts.GuestInfo {
+		spec.Config = append(spec.Config, &types.OptionValue{Key: "guestinfo." + key, Value: value})
+	}
+
+	if opts.RefreshMAC {
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+			card := d.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			card.AddressType = string(types.VirtualEthernetCardMacTypeGenerated)
+			card.MacAddress = ""
+			spec.Location.DeviceChange = append(spec.Location.DeviceChange, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationEdit,
+				Device:    d,
+			})
+		}
+	}
+
+	task, err := vm.InstantClone(ctx, spec)
+	if err != nil {
+		return nil, instantCloneError(err)
+	}
+
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, instantCloneError(err)
+	}
+
+	return object.NewVirtualMachine(c, info.Result.(types.ManagedObjectReference)), nil
+}
+
+// instantCloneError explains the faults vCenter raises when instant clone is unavailable
+func instantCloneError(err error) error {
+	if fault.Is(err, &types.NotSupported{}) || fault.Is(err, &types.MethodNotFound{}) {
+		return fmt.Errorf("instant clone is not supported by this vCenter or source VM: %w", err)
+	}
+	return err
+}
+
+// apiVersionAtLeast reports whether a vSphere API version such as "7.0.3.0"
+// is at least major.minor
+func apiVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+
+	gotMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	gotMinor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
@@ -0,0 +1,106 @@
+package vsphere
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// DatacenterRef returns the reference of the datacenter with the given MOID
+func DatacenterRef(moid string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "Datacenter", Value: moid}
+}
+
+// ClusterRef returns the reference of the cluster with the given MOID
+func ClusterRef(moid string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "ClusterComputeResource", Value: moid}
+}
+
+// NewFinder returns an inventory finder scoped to the datacenter with the given MOID
+func NewFinder(c *vim25.Client, datacenterMoid string) *find.Finder {
+	return find.NewFinder(c, true).SetDatacenter(object.NewDatacenter(c, DatacenterRef(datacenterMoid)))
+}
+
+// ResourcePool returns the resource pool at path, or the root resource pool
+// of the cluster with the given MOID when path is empty
+func ResourcePool(ctx context.Context, c *vim25.Client, datacenterMoid, clusterMoid, path string) (*object.ResourcePool, error) {
+	if path != "" {
+		return NewFinder(c, datacenterMoid).ResourcePool(ctx, path)
+	}
+	return object.NewClusterComputeResource(c, ClusterRef(clusterMoid)).ResourcePool(ctx)
+}
+
+// VMRef returns the reference of the VM with the given MOID
+func VMRef(moid string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: "VirtualMachine", Value: moid}
+}
+
+// ListVMs retrieves the given properties of every VM under root
+func ListVMs(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference, props []string) ([]mo.VirtualMachine, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var vms []mo.VirtualMachine
+	if err := v.Retrieve(ctx, []string{"VirtualMachine"}, props, &vms); err != nil {
+		return nil, err
+	}
+
+	return vms, nil
+}
+
+// RetrieveVM retrieves the given properties of a single VM
+func RetrieveVM(ctx context.Context, c *vim25.Client, ref types.ManagedObjectReference, props []string) (mo.VirtualMachine, error) {
+	var vm mo.VirtualMachine
+	err := property.DefaultCollector(c).RetrieveOne(ctx, ref, props, &vm)
+	return vm, err
+}
+
+// MatchName reports whether name matches the shell glob pattern
+func MatchName(pattern, name string) (bool, error) {
+	return path.Match(pattern, name)
+}
+
+// EntityNames resolves the names of the given managed objects
+func EntityNames(ctx context.Context, c *vim25.Client, refs []types.ManagedObjectReference) (map[types.ManagedObjectReference]string, error) {
+	names := make(map[types.ManagedObjectReference]string, len(refs))
+	if len(refs) == 0 {
+		return names, nil
+	}
+
+	var entities []mo.ManagedEntity
+	if err := property.DefaultCollector(c).Retrieve(ctx, refs, []string{"name"}, &entities); err != nil {
+		return nil, err
+	}
+	for _, e := range entities {
+		names[e.Reference()] = e.Name
+	}
+
+	return names, nil
+}
+
+// SortedNames resolves the names of the given managed objects and sorts them
+func SortedNames(ctx context.Context, c *vim25.Client, refs []types.ManagedObjectReference) ([]string, error) {
+	names, err := EntityNames(ctx, c, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]string, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	return sorted, nil
+}
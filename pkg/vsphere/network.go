@@ -0,0 +1,270 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Network types reported by NetworkSummary.Type
+const (
+	NetworkStandard    = "standard"
+	NetworkDistributed = "distributed"
+	NetworkOpaque      = "opaque"
+)
+
+// NetworkSummary is a port group as shown by network list
+type NetworkSummary struct {
+	Name string `json:"name" yaml:"name"`
+	Moid string `json:"moid" yaml:"moid"`
+	Type string `json:"type" yaml:"type"`
+	// Switch is the distributed switch, or the standard vSwitch names the
+	// port group is defined on across hosts
+	Switch     string `json:"switch,omitempty" yaml:"switch,omitempty"`
+	VLAN       string `json:"vlan,omitempty" yaml:"vlan,omitempty"`
+	NumHosts   int    `json:"numHosts" yaml:"numHosts"`
+	NumVMs     int    `json:"numVMs" yaml:"numVMs"`
+	Accessible bool   `json:"accessible" yaml:"accessible"`
+}
+
+// NetworkVM is a NIC of a VM attached to a port group
+type NetworkVM struct {
+	VM         string `json:"vm" yaml:"vm"`
+	Moid       string `json:"moid" yaml:"moid"`
+	PowerState string `json:"powerState" yaml:"powerState"`
+	NIC        string `json:"nic" yaml:"nic"`
+	MAC        string `json:"mac" yaml:"mac"`
+	Connected  bool   `json:"connected" yaml:"connected"`
+	IPAddress  string `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
+}
+
+// ListNetworks returns the standard, distributed and opaque port groups
+// under root, sorted by name. Distributed uplink port groups are left out.
+func ListNetworks(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference) ([]NetworkSummary, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"Network"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var networks []mo.Network
+	if err := v.Retrieve(ctx, []string{"Network"}, []string{"name", "summary", "host", "vm"}, &networks); err != nil {
+		return nil, err
+	}
+
+	var pgRefs []types.ManagedObjectReference
+	for _, n := range networks {
+		if n.Reference().Type == "DistributedVirtualPortgroup" {
+			pgRefs = append(pgRefs, n.Reference())
+		}
+	}
+
+	pc := property.DefaultCollector(c)
+	portgroups := make(map[types.ManagedObjectReference]types.DVPortgroupConfigInfo, len(pgRefs))
+	var switchRefs []types.ManagedObjectReference
+	if len(pgRefs) > 0 {
+		var pgs []mo.DistributedVirtualPortgroup
+		if err := pc.Retrieve(ctx, pgRefs, []string{"config"}, &pgs); err != nil {
+			return nil, err
+		}
+		for _, pg := range pgs {
+			portgroups[pg.Reference()] = pg.Config
+			if pg.Config.DistributedVirtualSwitch != nil {
+				switchRefs = append(switchRefs, *pg.Config.DistributedVirtualSwitch)
+			}
+		}
+	}
+	switchNames, err := EntityNames(ctx, c, dedupeRefs(switchRefs))
+	if err != nil {
+		return nil, err
+	}
+
+	standard, err := standardPortgroups(ctx, c, root)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]NetworkSummary, 0, len(networks))
+	for _, n := range networks {
+		s := NetworkSummary{
+			Name:     n.Name,
+			Moid:     n.Reference().Value,
+			NumHosts: len(n.Host),
+			NumVMs:   len(n.Vm),
+		}
+		if n.Summary != nil {
+			s.Accessible = n.Summary.GetNetworkSummary().Accessible
+		}
+
+		switch n.Reference().Type {
+		case "DistributedVirtualPortgroup":
+			cfg := portgroups[n.Reference()]
+			if boolValue(cfg.Uplink) {
+				continue
+			}
+			s.Type = NetworkDistributed
+			s.VLAN = PortgroupVLAN(cfg)
+			if cfg.DistributedVirtualSwitch != nil {
+				s.Switch = switchNames[*cfg.DistributedVirtualSwitch]
+			}
+		case "OpaqueNetwork":
+			s.Type = NetworkOpaque
+		default:
+			s.Type = NetworkStandard
+			if pg, ok := standard[n.Name]; ok {
+				s.Switch = strings.Join(sortedKeys(pg.switches), ", ")
+				s.VLAN = strings.Join(sortedKeys(pg.vlans), ", ")
+			}
+		}
+
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	return summaries, nil
+}
+
+// standardPortgroup collects the vSwitches and VLANs a standard port group
+// is defined with across hosts; they usually agree, but nothing enforces it
+type standardPortgroup struct {
+	switches map[string]bool
+	vlans    map[string]bool
+}
+
+// standardPortgroups reads the standard port group definitions of every
+// host under root, keyed by port group name
+func standardPortgroups(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference) (map[string]*standardPortgroup, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"HostSystem"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var hosts []mo.HostSystem
+	if err := v.Retrieve(ctx, []string{"HostSystem"}, []string{"config.network.portgroup"}, &hosts); err != nil {
+		return nil, err
+	}
+
+	portgroups := make(map[string]*standardPortgroup)
+	for _, h := range hosts {
+		if h.Config == nil || h.Config.Network == nil {
+			continue
+		}
+		for _, pg := range h.Config.Network.Portgroup {
+			s, ok := portgroups[pg.Spec.Name]
+			if !ok {
+				s = &standardPortgroup{switches: map[string]bool{}, vlans: map[string]bool{}}
+				portgroups[pg.Spec.Name] = s
+			}
+			s.switches[pg.Spec.VswitchName] = true
+			s.vlans[standardVLAN(pg.Spec.VlanId)] = true
+		}
+	}
+
+	return portgroups, nil
+}
+
+// standardVLAN describes the VLAN ID of a standard port group: 0 is
+// untagged and 4095 passes all VLANs to the guest
+func standardVLAN(id int32) string {
+	switch id {
+	case 0:
+		return "none"
+	case 4095:
+		return "trunk"
+	}
+	return fmt.Sprintf("%d", id)
+}
+
+// NetworkVMs lists the NICs of every VM attached to the network, sorted by
+// VM name and NIC label
+func NetworkVMs(ctx context.Context, c *vim25.Client, network object.NetworkReference) ([]NetworkVM, error) {
+	want, err := network.EthernetCardBackingInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pc := property.DefaultCollector(c)
+	var n mo.Network
+	if err := pc.RetrieveOne(ctx, network.Reference(), []string{"vm"}, &n); err != nil {
+		return nil, err
+	}
+
+	entries := []NetworkVM{}
+	if len(n.Vm) == 0 {
+		return entries, nil
+	}
+
+	var vms []mo.VirtualMachine
+	if err := pc.Retrieve(ctx, n.Vm, []string{"name", "runtime.powerState", "config.hardware.device", "guest.net"}, &vms); err != nil {
+		return nil, err
+	}
+
+	for _, vm := range vms {
+		if vm.Config == nil {
+			continue
+		}
+		devices := object.VirtualDeviceList(vm.Config.Hardware.Device)
+		for _, d := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+			nic := d.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			if !sameNetwork(nic.Backing, want) {
+				continue
+			}
+
+			entry := NetworkVM{
+				VM:         vm.Name,
+				Moid:       vm.Reference().Value,
+				PowerState: string(vm.Runtime.PowerState),
+				NIC:        deviceName(devices, d),
+				MAC:        nic.MacAddress,
+			}
+			if nic.Connectable != nil {
+				entry.Connected = nic.Connectable.Connected
+			}
+			if vm.Guest != nil {
+				for _, g := range vm.Guest.Net {
+					if g.DeviceConfigId == nic.Key && len(g.IpAddress) > 0 {
+						entry.IPAddress = strings.Join(g.IpAddress, ", ")
+					}
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].VM != entries[j].VM {
+			return entries[i].VM < entries[j].VM
+		}
+		return entries[i].NIC < entries[j].NIC
+	})
+
+	return entries, nil
+}
+
+// sameNetwork reports whether a NIC backing points at the network described
+// by want, as returned by EthernetCardBackingInfo
+func sameNetwork(backing, want types.BaseVirtualDeviceBackingInfo) bool {
+	switch w := want.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		b, ok := backing.(*types.VirtualEthernetCardNetworkBackingInfo)
+		return ok && b.DeviceName == w.DeviceName
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		b, ok := backing.(*types.VirtualEthernetCardDistributedVirtualPortBackingInfo)
+		return ok && b.Port.PortgroupKey == w.Port.PortgroupKey && b.Port.SwitchUuid == w.Port.SwitchUuid
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		b, ok := backing.(*types.VirtualEthernetCardOpaqueNetworkBackingInfo)
+		return ok && b.OpaqueNetworkId == w.OpaqueNetworkId
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Kinds of orphaned files reported by FindOrphans
+const (
+	OrphanDisk          = "disk"
+	OrphanSnapshotDelta = "snapshot-delta"
+	OrphanSnapshotState = "snapshot-state"
+)
+
+// snapshotDeltaPattern matches the delta disks vSphere creates for snapshots
+// (vm-000001.vmdk) and the legacy vm-delta.vmdk extents
+var snapshotDeltaPattern = regexp.MustCompile(`(-\d{6}|-delta)\.vmdk$`)
+
+// diskExtentPattern matches the data and tracking extents that belong to a
+// descriptor VMDK of the same base name
+var diskExtentPattern = regexp.MustCompile(`-(flat|delta|sesparse|ctk)\.vmdk$`)
+
+// OrphanFile is a VMDK or snapshot file no registered VM references
+type OrphanFile struct {
+	Path      string     `json:"path" yaml:"path"`
+	Kind      string     `json:"kind" yaml:"kind"`
+	SizeBytes int64      `json:"sizeBytes" yaml:"sizeBytes"`
+	Modified  *time.Time `json:"modified,omitempty" yaml:"modified,omitempty"`
+}
+
+// OrphanScan is the result of FindOrphans
+type OrphanScan struct {
+	Datastore string       `json:"datastore" yaml:"datastore"`
+	Orphans   []OrphanFile `json:"orphans" yaml:"orphans"`
+	// SkippedDirs are directories of VMs whose files could not be read
+	// (e.g. inaccessible VMs); nothing in them is reported
+	SkippedDirs []string `json:"skippedDirs,omitempty" yaml:"skippedDirs,omitempty"`
+}
+
+// FindOrphans walks dir on the datastore and reports the VMDKs, snapshot
+// deltas and snapshot state (.vmsn) files that are not part of the file
+// layout or disk chains of any registered VM or template.
+//
+// Hidden directories and the directories vSphere keeps unregistered disks
+// in on purpose (fcd for First Class Disks, contentlib-* for content library
+// items) are not scanned. vSAN and VVol datastores address files by object
+// ID rather than path and are refused.
+func FindOrphans(ctx context.Context, c *vim25.Client, ds *object.Datastore, dir string) (*OrphanScan, error) {
+	var props mo.Datastore
+	if err := ds.Properties(ctx, ds.Reference(), []string{"name", "summary.type", "vm"}, &props); err != nil {
+		return nil, err
+	}
+	switch props.Summary.Type {
+	case "vsan", "VVOL":
+		return nil, fmt.Errorf("orphan scanning is not supported on %s datastores", props.Summary.Type)
+	}
+
+	scan := &OrphanScan{Datastore: props.Name, Orphans: []OrphanFile{}}
+
+	referenced, skipped, err := referencedFiles(ctx, c, props.Vm)
+	if err != nil {
+		return nil, err
+	}
+
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec := types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{"*.vmdk", "*.vmsn"},
+		Details: &types.FileQueryFlags{
+			FileType:     true,
+			FileSize:     true,
+			Modification: true,
+			FileOwner:    types.NewBool(false),
+		},
+		Query: []types.BaseFileQuery{
+			&types.VmDiskFileQuery{},
+			&types.VmSnapshotFileQuery{},
+			&types.FileQuery{},
+		},
+	}
+	task, err := browser.SearchDatastoreSubFolders(ctx, ds.Path(dir), &spec)
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range info.Result.(types.ArrayOfHostDatastoreBrowserSearchResults).HostDatastoreBrowserSearchResults {
+		var folder object.DatastorePath
+		if !folder.FromString(res.FolderPath) {
+			continue
+		}
+		folderPath := strings.Trim(folder.Path, "/")
+		if ignoredOrphanDir(folderPath) || skipped[ds.Path(folderPath)] {
+			continue
+		}
+
+		names := make(map[string]bool, len(res.File))
+		for _, f := range res.File {
+			names[f.GetFileInfo().Path] = true
+		}
+
+		for _, f := range res.File {
+			fi := f.GetFileInfo()
+			p := path.Join(folderPath, fi.Path)
+			if referenced[ds.Path(p)] {
+				continue
+			}
+			// Extents go with their descriptor, which is reported instead
+			if loc := diskExtentPattern.FindStringIndex(fi.Path); loc != nil && names[fi.Path[:loc[0]]+".vmdk"] {
+				continue
+			}
+
+			orphan := OrphanFile{
+				Path:      ds.Path(p),
+				Kind:      OrphanDisk,
+				SizeBytes: fi.FileSize,
+				Modified:  fi.Modification,
+			}
+			switch {
+			case strings.HasSuffix(p, ".vmsn"):
+				orphan.Kind = OrphanSnapshotState
+			case snapshotDeltaPattern.MatchString(p):
+				orphan.Kind = OrphanSnapshotDelta
+			}
+			scan.Orphans = append(scan.Orphans, orphan)
+		}
+	}
+
+	for dir := range skipped {
+		if dir == ds.Path("") || strings.HasPrefix(dir, ds.Path("")+" ") {
+			scan.SkippedDirs = append(scan.SkippedDirs, dir)
+		}
+	}
+	sort.Strings(scan.SkippedDirs)
+	sort.Slice(scan.Orphans, func(i, j int) bool {
+		return scan.Orphans[i].Path < scan.Orphans[j].Path
+	})
+
+	return scan, nil
+}
+
+// referencedFiles returns the datastore paths ("[ds] dir/file") of every
+// file the given VMs use: their file layout (which covers all snapshot
+// files) and the full backing chain of each disk. Directories of VMs with
+// neither are returned as skipped.
+func referencedFiles(ctx context.Context, c *vim25.Client, refs []types.ManagedObjectReference) (map[string]bool, map[string]bool, error) {
+	referenced := make(map[string]bool)
+	skipped := make(map[string]bool)
+	if len(refs) == 0 {
+		return referenced, skipped, nil
+	}
+
+	var vms []mo.VirtualMachine
+	props := []string{"name", "layoutEx.file", "config.hardware.device", "summary.config.vmPathName"}
+	if err := property.DefaultCollector(c).Retrieve(ctx, refs, props, &vms); err != nil {
+		return nil, nil, err
+	}
+
+	add := func(name string) {
+		if p, ok := cleanDatastorePath(name, false); ok {
+			referenced[p] = true
+		}
+	}
+
+	for _, vm := range vms {
+		known := false
+		if vm.LayoutEx != nil {
+			for _, f := range vm.LayoutEx.File {
+				add(f.Name)
+				known = true
+			}
+		}
+		if vm.Config != nil {
+			for _, d := range object.VirtualDeviceList(vm.Config.Hardware.Device).SelectByType((*types.VirtualDisk)(nil)) {
+				for _, name := range diskChain(d.(*types.VirtualDisk).Backing) {
+					add(name)
+					known = true
+				}
+			}
+		}
+		if !known {
+			if p, ok := cleanDatastorePath(vm.Summary.Config.VmPathName, true); ok {
+				skipped[p] = true
+			}
+		}
+	}
+
+	return referenced, skipped, nil
+}
+
+// cleanDatastorePath normalizes a "[ds] dir/file" path, or returns the
+// path of its directory when dir is set, so paths compare equal however
+// vSphere formatted them
+func cleanDatastorePath(name string, dir bool) (string, bool) {
+	var p object.DatastorePath
+	if !p.FromString(name) {
+		return "", false
+	}
+	clean := path.Clean("/" + p.Path)
+	if dir {
+		clean = path.Dir(clean)
+	}
+	p.Path = strings.Trim(clean, "/")
+	return p.String(), true
+}
+
+// diskChain returns the file names of a disk backing and all its parents
+func diskChain(backing types.BaseVirtualDeviceBackingInfo) []string {
+	var names []string
+	switch b := backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		for ; b != nil; b = b.Parent {
+			names = append(names, b.FileName)
+		}
+	case *types.VirtualDiskSeSparseBackingInfo:
+		for ; b != nil; b = b.Parent {
+			names = append(names, b.FileName)
+		}
+	case *types.VirtualDiskSparseVer2BackingInfo:
+		for ; b != nil; b = b.Parent {
+			names = append(names, b.FileName)
+		}
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		for ; b != nil; b = b.Parent {
+			names = append(names, b.FileName)
+		}
+	default:
+		if fb, ok := backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+			names = append(names, fb.GetVirtualDeviceFileBackingInfo().FileName)
+		}
+	}
+	return names
+}
+
+// ignoredOrphanDir reports whether the directory holds files that are
+// legitimately not referenced by any VM
+func ignoredOrphanDir(dir string) bool {
+	for _, part := range strings.Split(dir, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasPrefix(part, "contentlib-") || part == "fcd" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// PowerOn powers on the VM and waits for the task to complete.
+// It is a no-op if the VM is already running.
+func PowerOn(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == types.VirtualMachinePowerStatePoweredOn {
+		return nil
+	}
+
+	task, err := vm.PowerOn(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// WaitForIP blocks until VMware Tools reports an IPv4 address for the VM
+// or the timeout expires
+func WaitForIP(ctx context.Context, vm *object.VirtualMachine, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ip, err := vm.WaitForIP(ctx, true)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("timed out after %s waiting for an IP address", timeout)
+		}
+		return "", err
+	}
+
+	return ip, nil
+}
+
+// PowerOff hard powers off the VM and waits for the task to complete.
+// It is a no-op if the VM is already powered off.
+func PowerOff(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == types.VirtualMachinePowerStatePoweredOff {
+		return nil
+	}
+
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// Reset hard resets a powered-on VM and waits for the task to complete
+func Reset(ctx context.Context, vm *object.VirtualMachine) error {
+	task, err := vm.Reset(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// Suspend suspends the VM and waits for the task to complete.
+// It is a no-op if the VM is already suspended.
+func Suspend(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+	if state == types.VirtualMachinePowerStateSuspended {
+		return nil
+	}
+
+	task, err := vm.Suspend(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// Shutdown asks the guest to shut down through VMware Tools. With wait or
+// force it then waits up to timeout for the VM to power off; if the guest
+// does not shut down in time and force is set, the VM is powered off hard.
+// It reports whether it had to. It is a no-op if the VM is already powered off.
+func Shutdown(ctx context.Context, vm *object.VirtualMachine, timeout time.Duration, force, wait bool) (bool, error) {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return false, err
+	}
+	if state == types.VirtualMachinePowerStatePoweredOff {
+		return false, nil
+	}
+
+	err = vm.ShutdownGuest(ctx)
+	if err == nil && !wait && !force {
+		return false, nil
+	}
+	if err == nil {
+		err = WaitForPowerState(ctx, vm, types.VirtualMachinePowerStatePoweredOff, timeout)
+	}
+	if err == nil {
+		return false, nil
+	}
+	if !force || ctx.Err() != nil {
+		return false, fmt.Errorf("guest shutdown failed: %w", err)
+	}
+
+	return true, PowerOff(ctx, vm)
+}
+
+// Reboot asks the guest to reboot through VMware Tools. With wait or force
+// it then waits up to timeout for the guest to restart; if it does not restart
+// in time and force is set, the VM is reset. It reports whether it had to.
+// With wait, a reset VM is also waited for until Tools is running again.
+func Reboot(ctx context.Context, vm *object.VirtualMachine, timeout time.Duration, force, wait bool) (bool, error) {
+	err := vm.RebootGuest(ctx)
+	if err == nil && !wait && !force {
+		return false, nil
+	}
+	if err == nil {
+		err = WaitForGuestRestart(ctx, vm, timeout)
+	}
+	if err == nil {
+		return false, nil
+	}
+	if !force || ctx.Err() != nil {
+		return false, fmt.Errorf("guest reboot failed: %w", err)
+	}
+
+	if err := Reset(ctx, vm); err != nil {
+		return true, err
+	}
+	if !wait {
+		return true, nil
+	}
+	return true, WaitForGuestRestart(ctx, vm, timeout)
+}
+
+// WaitForGuestRestart blocks up to timeout until VMware Tools has stopped and
+// is running again, following a guest reboot or a reset
+func WaitForGuestRestart(ctx context.Context, vm *object.VirtualMachine, timeout time.Duration) error {
+	// Tools stops before the guest goes down; wait for that first so the
+	// running status below is the one after the restart
+	if err := WaitForTools(ctx, vm, false, timeout); err != nil {
+		return err
+	}
+	return WaitForTools(ctx, vm, true, timeout)
+}
+
+// WaitForPowerState blocks until the VM reaches state or the timeout expires
+func WaitForPowerState(ctx context.Context, vm *object.VirtualMachine, state types.VirtualMachinePowerState, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := vm.WaitForPowerState(ctx, state); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s waiting for the VM to be %s", timeout, state)
+		}
+		return err
+	}
+	return nil
+}
+
+// WaitForTools blocks until VMware Tools is running (or not running) in the
+// guest, or the timeout expires
+func WaitForTools(ctx context.Context, vm *object.VirtualMachine, running bool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	want := string(types.VirtualMachineToolsRunningStatusGuestToolsRunning)
+	pc := property.DefaultCollector(vm.Client())
+	err := property.Wait(ctx, pc, vm.Reference(), []string{"guest.toolsRunningStatus"}, func(changes []types.PropertyChange) bool {
+		for _, c := range changes {
+			status, _ := c.Val.(string)
+			if (status == want) == running {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %s waiting for VMware Tools", timeout)
+		}
+		return err
+	}
+	return nil
+}
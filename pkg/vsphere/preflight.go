@@ -0,0 +1,225 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// CheckStatus is the outcome of a pre-flight rule, ordered pass < warn < fail
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+func (s CheckStatus) severity() int {
+	switch s {
+	case CheckFail:
+		return 2
+	case CheckWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// PreflightVM is what a pre-flight rule inspects
+type PreflightVM struct {
+	Props mo.VirtualMachine
+	Disks *DiskReport
+}
+
+// PreflightRule checks one migration requirement of a VM
+type PreflightRule struct {
+	Name        string
+	Description string
+	Check       func(vm PreflightVM) (CheckStatus, string)
+}
+
+// CheckResult is the outcome of one rule for one VM
+type CheckResult struct {
+	Rule    string      `json:"rule" yaml:"rule"`
+	Status  CheckStatus `json:"status" yaml:"status"`
+	Message string      `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// PreflightResult holds every rule outcome for a VM. Status is the worst outcome.
+type PreflightResult struct {
+	VM     string        `json:"vm" yaml:"vm"`
+	Moid   string        `json:"moid" yaml:"moid"`
+	Status CheckStatus   `json:"status" yaml:"status"`
+	Checks []CheckResult `json:"checks" yaml:"checks"`
+}
+
+// PreflightProps returns the VM properties RunPreflight needs
+func PreflightProps() []string {
+	return append(DiskReportProps(),
+		"config.guestId", "config.guestFullName", "config.template",
+		"runtime.powerState", "guest.toolsRunningStatus", "snapshot")
+}
+
+// RunPreflight evaluates rules against a VM retrieved with PreflightProps
+func RunPreflight(ctx context.Context, c *vim25.Client, props mo.VirtualMachine, rules []PreflightRule) (*PreflightResult, error) {
+	disks, err := DiskDetails(ctx, c, props)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := PreflightVM{Props: props, Disks: disks}
+	result := &PreflightResult{
+		VM:     props.Name,
+		Moid:   props.Reference().Value,
+		Status: CheckPass,
+	}
+
+	for _, rule := range rules {
+		status, message := rule.Check(vm)
+		result.Checks = append(result.Checks, CheckResult{Rule: rule.Name, Status: status, Message: message})
+		if status.severity() > result.Status.severity() {
+			result.Status = status
+		}
+	}
+
+	return result, nil
+}
+
+// PreflightRules is the default migration rule set, in report order
+var PreflightRules = []PreflightRule{
+	{Name: "disk-types", Description: "Disks use a supported backing", Check: checkDiskTypes},
+	{Name: "rdm", Description: "No raw device mappings", Check: checkRDM},
+	{Name: "shared-disks", Description: "No multi-writer or bus-shared disks", Check: checkSharedDisks},
+	{Name: "independent-disks", Description: "No independent disks", Check: checkIndependentDisks},
+	{Name: "cbt", Description: "Changed Block Tracking is enabled", Check: checkCBT},
+	{Name: "snapshots", Description: "No snapshots present", Check: checkSnapshots},
+	{Name: "tools", Description: "VMware Tools is running", Check: checkTools},
+	{Name: "guest-os", Description: "Guest OS is supported", Check: checkGuestOS},
+	{Name: "passthrough", Description: "No passthrough devices", Check: checkPassthrough},
+	{Name: "encryption", Description: "VM and disks are not encrypted", Check: checkEncryption},
+}
+
+// failDisks fails with the labels of the disks matching match, or passes
+func failDisks(vm PreflightVM, status CheckStatus, what string, match func(DiskDetail) bool) (CheckStatus, string) {
+	var labels []string
+	for _, d := range vm.Disks.Disks {
+		if match(d) {
+			labels = append(labels, d.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return CheckPass, ""
+	}
+	return status, fmt.Sprintf("%s: %s", what, strings.Join(labels, ", "))
+}
+
+func checkDiskTypes(vm PreflightVM) (CheckStatus, string) {
+	if status, msg := failDisks(vm, CheckFail, "unsupported disk backing", func(d DiskDetail) bool {
+		return d.Backing == BackingOther || d.Backing == BackingPMem
+	}); status != CheckPass {
+		return status, msg
+	}
+	return failDisks(vm, CheckWarn, "sparse or vVol disks", func(d DiskDetail) bool {
+		return d.Backing == BackingSparse || d.Backing == BackingVVol
+	})
+}
+
+func checkRDM(vm PreflightVM) (CheckStatus, string) {
+	return failDisks(vm, CheckFail, "raw device mappings", func(d DiskDetail) bool {
+		return d.Backing == BackingRDMPhysical || d.Backing == BackingRDMVirtual
+	})
+}
+
+func checkSharedDisks(vm PreflightVM) (CheckStatus, string) {
+	return failDisks(vm, CheckFail, "shared disks", func(d DiskDetail) bool {
+		return d.Sharing == string(types.VirtualDiskSharingSharingMultiWriter) ||
+			(d.BusSharing != "" && d.BusSharing != string(types.VirtualSCSISharingNoSharing))
+	})
+}
+
+func checkIndependentDisks(vm PreflightVM) (CheckStatus, string) {
+	return failDisks(vm, CheckFail, "independent disks", func(d DiskDetail) bool {
+		return strings.HasPrefix(d.DiskMode, "independent")
+	})
+}
+
+func checkCBT(vm PreflightVM) (CheckStatus, string) {
+	if !vm.Disks.CBTEnabled {
+		return CheckWarn, "ctkEnabled is off; only cold migration is possible"
+	}
+	return failDisks(vm, CheckWarn, "CBT not active on", func(d DiskDetail) bool {
+		return !d.CBT
+	})
+}
+
+func checkSnapshots(vm PreflightVM) (CheckStatus, string) {
+	if vm.Props.Snapshot == nil || len(vm.Props.Snapshot.RootSnapshotList) == 0 {
+		return CheckPass, ""
+	}
+	return CheckWarn, fmt.Sprintf("%d snapshot(s) present", countSnapshots(vm.Props.Snapshot.RootSnapshotList))
+}
+
+func checkTools(vm PreflightVM) (CheckStatus, string) {
+	if vm.Props.Runtime.PowerState != types.VirtualMachinePowerStatePoweredOn {
+		return CheckPass, "VM is not powered on"
+	}
+	if vm.Props.Guest == nil || vm.Props.Guest.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		return CheckWarn, "VMware Tools is not running; guest shutdown and IP discovery are unavailable"
+	}
+	return CheckPass, ""
+}
+
+// unsupportedGuestPrefixes are guest IDs of operating systems that cannot be
+// migrated. "otherGuest" covers unknown guests but not otherLinux*.
+var unsupportedGuestPrefixes = []string{
+	"darwin", "solaris", "freebsd", "netware", "os2", "dos", "win31", "win95", "win98", "winMe", "winNT", "otherGuest",
+}
+
+func checkGuestOS(vm PreflightVM) (CheckStatus, string) {
+	guestID := vm.Props.Config.GuestId
+	for _, prefix := range unsupportedGuestPrefixes {
+		if strings.HasPrefix(guestID, prefix) {
+			return CheckFail, fmt.Sprintf("unsupported guest OS %s (%s)", vm.Props.Config.GuestFullName, guestID)
+		}
+	}
+	return CheckPass, ""
+}
+
+func checkPassthrough(vm PreflightVM) (CheckStatus, string) {
+	var fail, warn []string
+	for _, d := range vm.Props.Config.Hardware.Device {
+		name := fmt.Sprintf("device %d", d.GetVirtualDevice().Key)
+		if info := d.GetVirtualDevice().DeviceInfo; info != nil {
+			name = info.GetDescription().Label
+		}
+		switch d.(type) {
+		case *types.VirtualPCIPassthrough, *types.VirtualSriovEthernetCard:
+			fail = append(fail, name)
+		case *types.VirtualUSB:
+			warn = append(warn, name)
+		}
+	}
+
+	switch {
+	case len(fail) > 0:
+		return CheckFail, "passthrough devices: " + strings.Join(fail, ", ")
+	case len(warn) > 0:
+		return CheckWarn, "USB devices: " + strings.Join(warn, ", ")
+	default:
+		return CheckPass, ""
+	}
+}
+
+func checkEncryption(vm PreflightVM) (CheckStatus, string) {
+	if vm.Disks.Encrypted {
+		return CheckFail, "VM is encrypted"
+	}
+	return failDisks(vm, CheckFail, "encrypted disks", func(d DiskDetail) bool {
+		return d.Encrypted
+	})
+}
@@ -0,0 +1,50 @@
+package vsphere
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/vim25/progress"
+)
+
+// Progress is a progress sink that prints the percentage complete of a task
+// or transfer on a single, rewritten line
+type Progress struct {
+	w     io.Writer
+	label string
+	done  chan struct{}
+}
+
+// NewProgress returns a progress sink that writes to w
+func NewProgress(w io.Writer, label string) *Progress {
+	return &Progress{w: w, label: label}
+}
+
+// Sink implements progress.Sinker
+func (p *Progress) Sink() chan<- progress.Report {
+	ch := make(chan progress.Report)
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		last := -1
+		for r := range ch {
+			if pct := int(r.Percentage()); pct != last {
+				fmt.Fprintf(p.w, "\r%s... %3d%%", p.label, pct)
+				last = pct
+			}
+		}
+		if last >= 0 {
+			fmt.Fprintln(p.w)
+		}
+	}()
+
+	return ch
+}
+
+// Wait blocks until the last progress line has been printed
+func (p *Progress) Wait() {
+	if p.done != nil {
+		<-p.done
+	}
+}
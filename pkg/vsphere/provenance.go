@@ -0,0 +1,101 @@
+package vsphere
+
+import (
+	"context"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Custom attributes vcli stamps on the clones it creates
+const (
+	AttrCreatedBy = "vcli.createdBy"
+	AttrSource    = "vcli.source"
+	AttrSnapshot  = "vcli.snapshot"
+	AttrCreatedAt = "vcli.createdAt"
+	AttrExpiresAt = "vcli.expiresAt"
+
+	createdByVcli = "vcli"
+)
+
+// Provenance describes where a clone came from
+type Provenance struct {
+	Source    string
+	Snapshot  string
+	CreatedAt time.Time
+	// ExpiresAt is when the clone may be garbage collected; zero means never
+	ExpiresAt time.Time
+}
+
+// StampClone records on the VM that it was cloned by vcli
+func StampClone(ctx context.Context, attrs *Attributes, ref types.ManagedObjectReference, p Provenance) error {
+	values := []struct{ name, value string }{
+		{AttrCreatedBy, createdByVcli},
+		{AttrSource, p.Source},
+		{AttrSnapshot, p.Snapshot},
+		{AttrCreatedAt, p.CreatedAt.UTC().Format(time.RFC3339)},
+	}
+	if !p.ExpiresAt.IsZero() {
+		values = append(values, struct{ name, value string }{AttrExpiresAt, p.ExpiresAt.UTC().Format(time.RFC3339)})
+	}
+
+	for _, v := range values {
+		if err := attrs.Set(ctx, ref, v.name, v.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsVcliClone reports whether the attribute values mark the VM as created by vcli
+func IsVcliClone(values map[string]string) bool {
+	return values[AttrCreatedBy] == createdByVcli
+}
+
+// ExpiresAt returns the expiry stamped on a clone, if it has a valid one
+func ExpiresAt(values map[string]string) (time.Time, bool) {
+	v, ok := values[AttrExpiresAt]
+	if !ok || v == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// IsLinkedClone reports whether any of the VM's disks chains up to a base
+// disk outside the VM's own directory, i.e. owned by another VM. Snapshot
+// deltas alone do not make a linked clone: their chain ends in the VM's own
+// base disk. The config.hardware.device and summary.config.vmPathName
+// properties must be loaded.
+func IsLinkedClone(vm mo.VirtualMachine) bool {
+	if vm.Config == nil {
+		return false
+	}
+	dir, ok := cleanDatastorePath(vm.Summary.Config.VmPathName, true)
+	if !ok {
+		return false
+	}
+
+	for _, d := range vm.Config.Hardware.Device {
+		disk, ok := d.(*types.VirtualDisk)
+		if !ok {
+			continue
+		}
+		chain := diskChain(disk.Backing)
+		if len(chain) < 2 {
+			continue
+		}
+		if base, ok := cleanDatastorePath(chain[len(chain)-1], true); ok && base != dir {
+			return true
+		}
+	}
+
+	return false
+}
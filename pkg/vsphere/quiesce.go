@@ -0,0 +1,92 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Snapshot consistency levels, from strongest to weakest
+const (
+	ConsistencyPoweredOff  = "powered-off"
+	ConsistencyMemory      = "memory"
+	ConsistencyApplication = "application"
+	ConsistencyFilesystem  = "filesystem"
+	ConsistencyCrash       = "crash"
+)
+
+// QuiesceCheck is the result of checking whether a VM can take a quiesced snapshot
+type QuiesceCheck struct {
+	PoweredOn bool
+	Supported bool
+	// Level is the consistency a quiesced snapshot achieves: application
+	// (Windows VSS) or filesystem (Linux freeze)
+	Level   string
+	Reasons []string
+}
+
+// CheckQuiesce verifies the preconditions of a quiesced snapshot: the VM is
+// powered on, VMware Tools is running, the guest OS supports quiescing and
+// no disk is excluded from or incompatible with it
+func CheckQuiesce(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) (*QuiesceCheck, error) {
+	props, err := RetrieveVM(ctx, c, vm.Reference(), append(DiskReportProps(), "runtime.powerState", "guest"))
+	if err != nil {
+		return nil, err
+	}
+
+	check := &QuiesceCheck{PoweredOn: props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn}
+	if !check.PoweredOn {
+		check.Reasons = append(check.Reasons, "VM is not powered on")
+		return check, nil
+	}
+
+	g := props.Guest
+	if g == nil || g.ToolsRunningStatus != string(types.VirtualMachineToolsRunningStatusGuestToolsRunning) {
+		check.Reasons = append(check.Reasons, "VMware Tools is not running")
+	}
+
+	var family string
+	if g != nil {
+		family = g.GuestFamily
+	}
+	switch types.VirtualMachineGuestOsFamily(family) {
+	case types.VirtualMachineGuestOsFamilyWindowsGuest:
+		check.Level = ConsistencyApplication
+	case types.VirtualMachineGuestOsFamilyLinuxGuest:
+		check.Level = ConsistencyFilesystem
+	case "":
+		check.Reasons = append(check.Reasons, "guest OS family is unknown")
+	default:
+		check.Reasons = append(check.Reasons, fmt.Sprintf("guest OS family %s does not support quiescing", family))
+	}
+
+	disks, err := DiskDetails(ctx, c, props)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range disks.Disks {
+		switch {
+		case d.Backing == BackingRDMPhysical:
+			check.Reasons = append(check.Reasons, fmt.Sprintf("%s is a physical-mode RDM", d.Label))
+		case d.Sharing == string(types.VirtualDiskSharingSharingMultiWriter):
+			check.Reasons = append(check.Reasons, fmt.Sprintf("%s uses multi-writer sharing", d.Label))
+		}
+	}
+	if disks.Encrypted {
+		check.Reasons = append(check.Reasons, "VM is encrypted")
+	}
+
+	check.Supported = len(check.Reasons) == 0
+	return check, nil
+}
+
+// IsQuiesceFault reports whether err is the guest failing to quiesce
+func IsQuiesceFault(err error) bool {
+	return fault.Is(err, &types.ApplicationQuiesceFault{}) ||
+		fault.Is(err, &types.FilesystemQuiesceFault{}) ||
+		fault.Is(err, &types.ToolsUnavailable{})
+}
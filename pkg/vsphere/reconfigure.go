@@ -0,0 +1,368 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ReconfigureOptions describes the changes made by PlanReconfigure.
+// Zero values leave the corresponding setting unchanged.
+type ReconfigureOptions struct {
+	CPUs           int32
+	CoresPerSocket int32
+	MemoryMB       int64
+	// AddDisks are the capacities in bytes of new thin disks, created on
+	// the first SCSI controller in the VM's home datastore
+	AddDisks []int64
+	// ResizeDisks maps disk labels to their new capacity in bytes
+	ResizeDisks     map[string]int64
+	RemoveDisks     []string
+	DeleteDiskFiles bool
+	// AddNICs are the networks to connect new NICs of type NICType to
+	AddNICs    []string
+	NICType    string
+	RemoveNICs []string
+	// SetNetworks maps NIC labels to the network to move them to
+	SetNetworks map[string]string
+	// ExtraConfig sets advanced settings; an empty value removes the key
+	ExtraConfig map[string]string
+	Annotation  *string
+}
+
+// Reconfiguration is a computed config spec and the changes it makes, in
+// the same form as a configuration diff
+type Reconfiguration struct {
+	Spec    types.VirtualMachineConfigSpec
+	Changes []Change
+}
+
+// PlanReconfigure computes the config spec that applies opts to the VM,
+// without changing anything. It refuses CPU and memory changes the VM
+// cannot take while powered on, based on its hot-add settings.
+func PlanReconfigure(ctx context.Context, c *vim25.Client, datacenterMoid string, vm *object.VirtualMachine, opts ReconfigureOptions) (*Reconfiguration, error) {
+	props, err := RetrieveVM(ctx, c, vm.Reference(), []string{"config", "runtime.powerState"})
+	if err != nil {
+		return nil, err
+	}
+	cfg := props.Config
+	if cfg == nil {
+		return nil, fmt.Errorf("VM %s has no configuration (is it inaccessible?)", vm.Reference().Value)
+	}
+	poweredOn := props.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn
+
+	before, err := ConfigFields(ctx, c, cfg)
+	if err != nil {
+		return nil, err
+	}
+	before["settings/annotation"] = cfg.Annotation
+	after := make(map[string]string, len(before))
+	for k, v := range before {
+		after[k] = v
+	}
+
+	var spec types.VirtualMachineConfigSpec
+
+	if err := planCompute(&spec, after, cfg, poweredOn, opts); err != nil {
+		return nil, err
+	}
+
+	finder := NewFinder(c, datacenterMoid)
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+
+	// A device can take a single change; labels are matched loosely, so
+	// conflicts are detected on the devices they resolve to
+	claimed := make(map[int32]string)
+	claim := func(d types.BaseVirtualDevice, flag string) error {
+		key := d.GetVirtualDevice().Key
+		if prev, ok := claimed[key]; ok {
+			return fmt.Errorf("%s is given to both %s and %s", deviceName(devices, d), prev, flag)
+		}
+		claimed[key] = flag
+		return nil
+	}
+
+	for _, label := range sortedKeys(opts.ResizeDisks) {
+		disk, err := findDisk(devices, label)
+		if err != nil {
+			return nil, err
+		}
+		if err := claim(disk, "--resize-disk"); err != nil {
+			return nil, err
+		}
+		size := opts.ResizeDisks[label]
+		if size < disk.CapacityInBytes {
+			return nil, fmt.Errorf("%s can only grow: it is %d bytes and %d was requested", label, disk.CapacityInBytes, size)
+		}
+		if size == disk.CapacityInBytes {
+			continue
+		}
+		disk.CapacityInBytes = size
+		disk.CapacityInKB = size / 1024
+		spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    disk,
+		})
+		after["disks/"+deviceName(devices, disk)+"/capacity"] = fmt.Sprint(size)
+	}
+
+	for _, label := range opts.RemoveDisks {
+		disk, err := findDisk(devices, label)
+		if err != nil {
+			return nil, err
+		}
+		if err := claim(disk, "--remove-disk"); err != nil {
+			return nil, err
+		}
+		change := &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationRemove,
+			Device:    disk,
+		}
+		if opts.DeleteDiskFiles {
+			change.FileOperation = types.VirtualDeviceConfigSpecFileOperationDestroy
+		}
+		spec.DeviceChange = append(spec.DeviceChange, change)
+		deleteFields(after, "disks/"+deviceName(devices, disk)+"/")
+	}
+
+	if len(opts.AddDisks) > 0 {
+		controller, err := devices.FindSCSIController("")
+		if err != nil {
+			return nil, fmt.Errorf("cannot add disks: %w", err)
+		}
+		var home object.DatastorePath
+		if !home.FromString(cfg.Files.VmPathName) {
+			return nil, fmt.Errorf("cannot parse VM home path %q", cfg.Files.VmPathName)
+		}
+		ds, err := finder.Datastore(ctx, home.Datastore)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, size := range opts.AddDisks {
+			disk := devices.CreateDisk(controller, ds.Reference(), "")
+			disk.CapacityInBytes = size
+			disk.CapacityInKB = size / 1024
+			// Keep the new disk in the list so the next one gets another unit number
+			devices = append(devices, disk)
+			spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+				Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+				FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+				Device:        disk,
+			})
+			prefix := fmt.Sprintf("disks/new disk %d/", i+1)
+			after[prefix+"capacity"] = fmt.Sprint(size)
+			after[prefix+"controller"] = deviceLabel(devices, controller.Key)
+			after[prefix+"unit"] = fmt.Sprint(*disk.UnitNumber)
+			after[prefix+"thin"] = "true"
+		}
+	}
+
+	for _, label := range sortedKeys(opts.SetNetworks) {
+		card, err := findNIC(devices, label)
+		if err != nil {
+			return nil, err
+		}
+		if err := claim(card.(types.BaseVirtualDevice), "--set-network"); err != nil {
+			return nil, err
+		}
+		name := opts.SetNetworks[label]
+		backing, err := networkBacking(ctx, finder, name)
+		if err != nil {
+			return nil, err
+		}
+		card.GetVirtualEthernetCard().Backing = backing
+		spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationEdit,
+			Device:    card.(types.BaseVirtualDevice),
+		})
+		after["networks/"+deviceName(devices, card.(types.BaseVirtualDevice))+"/network"] = name
+	}
+
+	for _, label := range opts.RemoveNICs {
+		card, err := findNIC(devices, label)
+		if err != nil {
+			return nil, err
+		}
+		if err := claim(card.(types.BaseVirtualDevice), "--remove-nic"); err != nil {
+			return nil, err
+		}
+		spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationRemove,
+			Device:    card.(types.BaseVirtualDevice),
+		})
+		deleteFields(after, "networks/"+deviceName(devices, card.(types.BaseVirtualDevice))+"/")
+	}
+
+	for i, name := range opts.AddNICs {
+		backing, err := networkBacking(ctx, finder, name)
+		if err != nil {
+			return nil, err
+		}
+		nic, err := devices.CreateEthernetCard(opts.NICType, backing)
+		if err != nil {
+			return nil, err
+		}
+		spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation: types.VirtualDeviceConfigSpecOperationAdd,
+			Device:    nic,
+		})
+		prefix := fmt.Sprintf("networks/new NIC %d/", i+1)
+		after[prefix+"adapter"] = devices.Type(nic)
+		after[prefix+"network"] = name
+	}
+
+	for _, key := range sortedKeys(opts.ExtraConfig) {
+		value := opts.ExtraConfig[key]
+		current, exists := before["extraConfig/"+key]
+		if value == current && (exists || value == "") {
+			continue
+		}
+		spec.ExtraConfig = append(spec.ExtraConfig, &types.OptionValue{Key: key, Value: value})
+		if value == "" {
+			delete(after, "extraConfig/"+key)
+		} else {
+			after["extraConfig/"+key] = value
+		}
+	}
+
+	if opts.Annotation != nil && *opts.Annotation != cfg.Annotation {
+		spec.Annotation = *opts.Annotation
+		after["settings/annotation"] = *opts.Annotation
+	}
+
+	return &Reconfiguration{Spec: spec, Changes: DiffFields(before, after)}, nil
+}
+
+// ApplyReconfigure reconfigures the VM with a planned spec and waits for the
+// task to complete
+func ApplyReconfigure(ctx context.Context, vm *object.VirtualMachine, plan *Reconfiguration) error {
+	task, err := vm.Reconfigure(ctx, plan.Spec)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// planCompute validates CPU and memory changes against the VM's power state
+// and hot-add settings and adds them to spec
+func planCompute(spec *types.VirtualMachineConfigSpec, after map[string]string, cfg *types.VirtualMachineConfigInfo, poweredOn bool, opts ReconfigureOptions) error {
+	hw := cfg.Hardware
+
+	cpus := hw.NumCPU
+	if opts.CPUs > 0 && opts.CPUs != hw.NumCPU {
+		if poweredOn && opts.CPUs > hw.NumCPU && !boolValue(cfg.CpuHotAddEnabled) {
+			return fmt.Errorf("CPU hot-add is disabled on this VM; power it off to add CPUs")
+		}
+		if poweredOn && opts.CPUs < hw.NumCPU && !boolValue(cfg.CpuHotRemoveEnabled) {
+			return fmt.Errorf("CPU hot-remove is disabled on this VM; power it off to remove CPUs")
+		}
+		cpus = opts.CPUs
+		spec.NumCPUs = opts.CPUs
+		after["hardware/cpus"] = fmt.Sprint(opts.CPUs)
+	}
+
+	cores := hw.NumCoresPerSocket
+	if opts.CoresPerSocket > 0 && opts.CoresPerSocket != hw.NumCoresPerSocket {
+		if poweredOn {
+			return fmt.Errorf("cores per socket can only be changed while the VM is powered off")
+		}
+		cores = opts.CoresPerSocket
+		spec.NumCoresPerSocket = opts.CoresPerSocket
+		after["hardware/coresPerSocket"] = fmt.Sprint(opts.CoresPerSocket)
+	}
+	if cores > 0 && cpus%cores != 0 {
+		return fmt.Errorf("%d CPUs cannot be split into sockets of %d cores", cpus, cores)
+	}
+
+	current := int64(hw.MemoryMB)
+	if opts.MemoryMB > 0 && opts.MemoryMB != current {
+		if opts.MemoryMB%4 != 0 {
+			return fmt.Errorf("memory must be a multiple of 4 MB")
+		}
+		if poweredOn {
+			if opts.MemoryMB < current {
+				return fmt.Errorf("memory cannot be reduced while the VM is powered on")
+			}
+			if !boolValue(cfg.MemoryHotAddEnabled) {
+				return fmt.Errorf("memory hot-add is disabled on this VM; power it off to add memory")
+			}
+			if cfg.HotPlugMemoryLimit > 0 && opts.MemoryMB > cfg.HotPlugMemoryLimit {
+				return fmt.Errorf("memory can be hot-added up to %d MB; power the VM off to go beyond", cfg.HotPlugMemoryLimit)
+			}
+			if inc := cfg.HotPlugMemoryIncrementSize; inc > 0 && (opts.MemoryMB-current)%inc != 0 {
+				return fmt.Errorf("hot-added memory must be a multiple of %d MB", inc)
+			}
+		}
+		spec.MemoryMB = opts.MemoryMB
+		after["hardware/memoryMB"] = fmt.Sprint(opts.MemoryMB)
+	}
+
+	return nil
+}
+
+// findDevice finds a device by its label (e.g. "Hard disk 2") or by its
+// generated name (e.g. "disk-1000-1")
+func findDevice(devices object.VirtualDeviceList, label string) types.BaseVirtualDevice {
+	for _, d := range devices {
+		if info := d.GetVirtualDevice().DeviceInfo; info != nil && strings.EqualFold(info.GetDescription().Label, label) {
+			return d
+		}
+	}
+	return devices.Find(label)
+}
+
+func findDisk(devices object.VirtualDeviceList, label string) (*types.VirtualDisk, error) {
+	disk, ok := findDevice(devices, label).(*types.VirtualDisk)
+	if !ok {
+		return nil, fmt.Errorf("no disk labelled %q", label)
+	}
+	return disk, nil
+}
+
+func findNIC(devices object.VirtualDeviceList, label string) (types.BaseVirtualEthernetCard, error) {
+	card, ok := findDevice(devices, label).(types.BaseVirtualEthernetCard)
+	if !ok {
+		return nil, fmt.Errorf("no network adapter labelled %q", label)
+	}
+	return card, nil
+}
+
+// deviceName returns the label ConfigFields uses for a device
+func deviceName(devices object.VirtualDeviceList, d types.BaseVirtualDevice) string {
+	if info := d.GetVirtualDevice().DeviceInfo; info != nil {
+		return info.GetDescription().Label
+	}
+	return devices.Name(d)
+}
+
+func networkBacking(ctx context.Context, finder *find.Finder, name string) (types.BaseVirtualDeviceBackingInfo, error) {
+	network, err := finder.Network(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("network %q: %w", name, err)
+	}
+	return network.EthernetCardBackingInfo(ctx)
+}
+
+func deleteFields(fields map[string]string, prefix string) {
+	for k := range fields {
+		if strings.HasPrefix(k, prefix) {
+			delete(fields, k)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,211 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Disk formats a storage relocation can convert disks to
+const (
+	DiskFormatThin        = "thin"
+	DiskFormatThick       = "thick"
+	DiskFormatEagerZeroed = "eager-zeroed"
+)
+
+// RelocateTarget is where a VM is moved to. Nil fields are left unchanged.
+type RelocateTarget struct {
+	Host      *object.HostSystem
+	Pool      *object.ResourcePool
+	Datastore *object.Datastore
+	// DiskFormat converts every disk during a storage move; empty keeps
+	// the current format
+	DiskFormat string
+}
+
+// CheckIssue is an error or warning reported by the vSphere check APIs
+type CheckIssue struct {
+	Level   string `json:"level" yaml:"level"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// RelocateSpec builds the relocate spec that moves the VM to target
+func RelocateSpec(ctx context.Context, vm *object.VirtualMachine, target RelocateTarget) (types.VirtualMachineRelocateSpec, error) {
+	var spec types.VirtualMachineRelocateSpec
+
+	if target.Host != nil {
+		ref := target.Host.Reference()
+		spec.Host = &ref
+	}
+	if target.Pool != nil {
+		ref := target.Pool.Reference()
+		spec.Pool = &ref
+	}
+	if target.Datastore == nil {
+		if target.DiskFormat != "" {
+			return spec, fmt.Errorf("disk format conversion requires a target datastore")
+		}
+		return spec, nil
+	}
+
+	ds := target.Datastore.Reference()
+	spec.Datastore = &ds
+	if target.DiskFormat == "" {
+		return spec, nil
+	}
+
+	var thin, eager bool
+	switch target.DiskFormat {
+	case DiskFormatThin:
+		thin = true
+	case DiskFormatThick:
+	case DiskFormatEagerZeroed:
+		eager = true
+	default:
+		return spec, fmt.Errorf("invalid disk format %q (must be %s, %s or %s)", target.DiskFormat, DiskFormatThin, DiskFormatThick, DiskFormatEagerZeroed)
+	}
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return spec, err
+	}
+	for _, d := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := d.(*types.VirtualDisk)
+		b, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if !ok {
+			return spec, fmt.Errorf("%s is not a flat disk and cannot be converted", deviceName(devices, disk))
+		}
+		spec.Disk = append(spec.Disk, types.VirtualMachineRelocateSpecDiskLocator{
+			DiskId:    disk.Key,
+			Datastore: ds,
+			DiskBackingInfo: &types.VirtualDiskFlatVer2BackingInfo{
+				DiskMode:        b.DiskMode,
+				ThinProvisioned: types.NewBool(thin),
+				EagerlyScrub:    types.NewBool(eager),
+			},
+		})
+	}
+
+	return spec, nil
+}
+
+// CheckRelocate runs the vSphere provisioning checks for spec and, when the
+// VM moves to another host, the compatibility checks for that host. The
+// check APIs are only available on vCenter.
+func CheckRelocate(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec) ([]CheckIssue, error) {
+	if c.ServiceContent.VmProvisioningChecker == nil || c.ServiceContent.VmCompatibilityChecker == nil {
+		return nil, fmt.Errorf("the vSphere check APIs are not available (not connected to vCenter)")
+	}
+
+	results, err := object.NewVmProvisioningChecker(c).CheckRelocate(ctx, vm.Reference(), spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Host != nil {
+		compat, err := object.NewVmCompatibilityChecker(c).CheckCompatibility(ctx, vm.Reference(), spec.Host, spec.Pool)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, compat...)
+	}
+
+	var issues []CheckIssue
+	seen := make(map[CheckIssue]bool)
+	add := func(level string, faults []types.LocalizedMethodFault) {
+		for _, f := range faults {
+			issue := CheckIssue{Level: level, Message: faultMessage(f)}
+			if !seen[issue] {
+				seen[issue] = true
+				issues = append(issues, issue)
+			}
+		}
+	}
+	for _, r := range results {
+		add("error", r.Error)
+		add("warning", r.Warning)
+	}
+
+	return issues, nil
+}
+
+// Relocate moves the VM according to spec and waits for the task to
+// complete, reporting progress to sink if it is not nil
+func Relocate(ctx context.Context, vm *object.VirtualMachine, spec types.VirtualMachineRelocateSpec, sink progress.Sinker) error {
+	task, err := vm.Relocate(ctx, spec, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		return err
+	}
+
+	if sink == nil {
+		_, err = task.WaitForResult(ctx)
+	} else {
+		_, err = task.WaitForResult(ctx, sink)
+	}
+	return err
+}
+
+// VMLocation resolves the names of the host and datastores a VM
+// currently runs on, for reporting before and after a move
+func VMLocation(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) (host string, datastores []string, err error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"runtime.host", "datastore"}, &props); err != nil {
+		return "", nil, err
+	}
+
+	if props.Runtime.Host != nil {
+		names, err := EntityNames(ctx, c, []types.ManagedObjectReference{*props.Runtime.Host})
+		if err != nil {
+			return "", nil, err
+		}
+		host = names[*props.Runtime.Host]
+	}
+
+	datastores, err = SortedNames(ctx, c, props.Datastore)
+	return host, datastores, err
+}
+
+// LeavesComputeResource reports whether moving the VM to host takes it to
+// another standalone host or cluster, which requires a resource pool there
+func LeavesComputeResource(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine, host *object.HostSystem) (bool, error) {
+	pc := property.DefaultCollector(c)
+
+	var props mo.VirtualMachine
+	if err := pc.RetrieveOne(ctx, vm.Reference(), []string{"runtime.host"}, &props); err != nil {
+		return false, err
+	}
+	if props.Runtime.Host == nil {
+		return true, nil
+	}
+
+	var hosts []mo.HostSystem
+	if err := pc.Retrieve(ctx, []types.ManagedObjectReference{*props.Runtime.Host, host.Reference()}, []string{"parent"}, &hosts); err != nil {
+		return false, err
+	}
+	parents := make(map[types.ManagedObjectReference]types.ManagedObjectReference, len(hosts))
+	for _, h := range hosts {
+		if h.Parent != nil {
+			parents[h.Reference()] = *h.Parent
+		}
+	}
+	return parents[*props.Runtime.Host] != parents[host.Reference()], nil
+}
+
+// faultMessage returns the localized message of a fault, or its type when
+// vCenter did not provide one
+func faultMessage(f types.LocalizedMethodFault) string {
+	if f.LocalizedMessage != "" {
+		return f.LocalizedMessage
+	}
+	if f.Fault != nil {
+		return reflect.TypeOf(f.Fault).Elem().Name()
+	}
+	return "unknown fault"
+}
@@ -0,0 +1,184 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	moidPattern = regexp.MustCompile(`^vm-\d+$`)
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// AmbiguousVMError is returned when a VM argument matches more than one VM
+type AmbiguousVMError struct {
+	Query      string
+	Candidates []string
+}
+
+func (e *AmbiguousVMError) Error() string {
+	return fmt.Sprintf("%q matches %d VMs, use an inventory path or MOID instead:\n  %s",
+		e.Query, len(e.Candidates), strings.Join(e.Candidates, "\n  "))
+}
+
+// ResolveVM finds the VM a command argument refers to, in the datacenter with
+// the given MOID. The argument may be:
+//
+//	vm-1234                    a managed object ID
+//	/dc/vm/folder/name         an absolute inventory path
+//	folder/name                a path relative to the datacenter's VM folder
+//	4210b6a2-...-9c2f1e3d4a5b  a BIOS or instance UUID
+//	10.0.0.12                  an IP address reported by VMware Tools
+//	name                       a VM name
+//
+// It fails with an *AmbiguousVMError listing the candidates when the argument
+// matches more than one VM.
+func ResolveVM(ctx context.Context, c *vim25.Client, datacenterMoid, query string) (*object.VirtualMachine, error) {
+	switch {
+	case moidPattern.MatchString(query):
+		return vmByMoid(ctx, c, query)
+	case strings.Contains(query, "/"):
+		return vmByPath(ctx, c, datacenterMoid, query)
+	case uuidPattern.MatchString(query):
+		return vmByUUID(ctx, c, datacenterMoid, query)
+	case net.ParseIP(query) != nil:
+		return vmByIP(ctx, c, datacenterMoid, query)
+	default:
+		return vmByName(ctx, c, datacenterMoid, query)
+	}
+}
+
+// isNameQuery reports whether ResolveVM treats query as a bare VM name
+func isNameQuery(query string) bool {
+	return !moidPattern.MatchString(query) && !strings.Contains(query, "/") &&
+		!uuidPattern.MatchString(query) && net.ParseIP(query) == nil
+}
+
+// FindChildVM finds the VM with the given name directly in a folder, where
+// vSphere guarantees names are unique
+func FindChildVM(ctx context.Context, c *vim25.Client, folder types.ManagedObjectReference, name string) (*object.VirtualMachine, error) {
+	ref, err := object.NewSearchIndex(c).FindChild(ctx, object.NewFolder(c, folder), name)
+	if err != nil {
+		return nil, err
+	}
+	vm, ok := ref.(*object.VirtualMachine)
+	if !ok {
+		return nil, fmt.Errorf("VM %q not found in folder %s", name, folder.Value)
+	}
+	return vm, nil
+}
+
+func vmByMoid(ctx context.Context, c *vim25.Client, moid string) (*object.VirtualMachine, error) {
+	ref := VMRef(moid)
+	if _, err := RetrieveVM(ctx, c, ref, []string{"name"}); err != nil {
+		return nil, fmt.Errorf("VM %s not found: %w", moid, err)
+	}
+	return object.NewVirtualMachine(c, ref), nil
+}
+
+func vmByPath(ctx context.Context, c *vim25.Client, datacenterMoid, p string) (*object.VirtualMachine, error) {
+	// A leading "./" makes the finder resolve the path against the VM folder
+	search := p
+	if !strings.HasPrefix(p, "/") {
+		search = "./" + p
+	}
+
+	vms, err := NewFinder(c, datacenterMoid).VirtualMachineList(ctx, search)
+	if err != nil {
+		var notFound *find.NotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("no VM at path %q", p)
+		}
+		return nil, err
+	}
+	if len(vms) > 1 {
+		candidates := make([]string, len(vms))
+		for i, vm := range vms {
+			candidates[i] = fmt.Sprintf("%s (%s)", vm.InventoryPath, vm.Reference().Value)
+		}
+		sort.Strings(candidates)
+		return nil, &AmbiguousVMError{Query: p, Candidates: candidates}
+	}
+	return vms[0], nil
+}
+
+func vmByUUID(ctx context.Context, c *vim25.Client, datacenterMoid, uuid string) (*object.VirtualMachine, error) {
+	si := object.NewSearchIndex(c)
+	dc := object.NewDatacenter(c, DatacenterRef(datacenterMoid))
+
+	var refs []types.ManagedObjectReference
+	for _, instance := range []bool{false, true} {
+		found, err := si.FindAllByUuid(ctx, dc, uuid, true, &instance)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range found {
+			refs = append(refs, r.Reference())
+		}
+	}
+
+	return singleVM(ctx, c, uuid, dedupeRefs(refs), "no VM with UUID %s")
+}
+
+func vmByIP(ctx context.Context, c *vim25.Client, datacenterMoid, ip string) (*object.VirtualMachine, error) {
+	dc := object.NewDatacenter(c, DatacenterRef(datacenterMoid))
+	found, err := object.NewSearchIndex(c).FindAllByIp(ctx, dc, ip, true)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]types.ManagedObjectReference, len(found))
+	for i, r := range found {
+		refs[i] = r.Reference()
+	}
+
+	return singleVM(ctx, c, ip, refs, "no VM with IP address %s (VMware Tools must be running to report it)")
+}
+
+func vmByName(ctx context.Context, c *vim25.Client, datacenterMoid, name string) (*object.VirtualMachine, error) {
+	vms, err := ListVMs(ctx, c, DatacenterRef(datacenterMoid), []string{"name"})
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []types.ManagedObjectReference
+	for _, vm := range vms {
+		if vm.Name == name {
+			refs = append(refs, vm.Reference())
+		}
+	}
+
+	return singleVM(ctx, c, name, refs, "VM %q not found")
+}
+
+// singleVM returns the only VM in refs, or an error naming the candidates
+func singleVM(ctx context.Context, c *vim25.Client, query string, refs []types.ManagedObjectReference, notFound string) (*object.VirtualMachine, error) {
+	switch len(refs) {
+	case 0:
+		return nil, fmt.Errorf(notFound, query)
+	case 1:
+		return object.NewVirtualMachine(c, refs[0]), nil
+	}
+
+	candidates := make([]string, len(refs))
+	for i, ref := range refs {
+		p, err := find.InventoryPath(ctx, c, ref)
+		if err != nil {
+			p = ref.Value
+		}
+		candidates[i] = fmt.Sprintf("%s (%s)", p, ref.Value)
+	}
+	sort.Strings(candidates)
+
+	return nil, &AmbiguousVMError{Query: query, Candidates: candidates}
+}
@@ -0,0 +1,81 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// FindTemplate finds the template with the given name under root
+func FindTemplate(ctx context.Context, c *vim25.Client, root types.ManagedObjectReference, name string) (*object.VirtualMachine, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	refs, err := v.Find(ctx, []string{"VirtualMachine"}, property.Match{"name": name, "config.template": true})
+	if err != nil {
+		return nil, err
+	}
+
+	return singleVM(ctx, c, name, refs, "template %q not found")
+}
+
+// ResolveTemplate finds the template a command argument refers to. It
+// accepts the same forms as ResolveVM, but a bare name only matches
+// templates, and any other form must resolve to a template.
+func ResolveTemplate(ctx context.Context, c *vim25.Client, datacenterMoid, query string) (*object.VirtualMachine, error) {
+	if isNameQuery(query) {
+		return FindTemplate(ctx, c, DatacenterRef(datacenterMoid), query)
+	}
+
+	vm, err := ResolveVM(ctx, c, datacenterMoid, query)
+	if err != nil {
+		return nil, err
+	}
+	props, err := RetrieveVM(ctx, c, vm.Reference(), []string{"config.template"})
+	if err != nil {
+		return nil, err
+	}
+	if props.Config == nil || !props.Config.Template {
+		return nil, fmt.Errorf("%s is a VM, not a template", query)
+	}
+	return vm, nil
+}
+
+// CloneTemplate creates a full, powered-off clone of a template in the
+// template's folder, placing it in pool
+func CloneTemplate(ctx context.Context, c *vim25.Client, tmpl *object.VirtualMachine, name string, pool types.ManagedObjectReference) (*object.VirtualMachine, error) {
+	var props mo.VirtualMachine
+	if err := tmpl.Properties(ctx, tmpl.Reference(), []string{"parent"}, &props); err != nil {
+		return nil, err
+	}
+	if props.Parent == nil {
+		return nil, fmt.Errorf("template %s has no parent folder", tmpl.Reference().Value)
+	}
+
+	spec := types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{Pool: &pool},
+		PowerOn:  false,
+		Template: false,
+	}
+
+	task, err := tmpl.Clone(ctx, object.NewFolder(c, *props.Parent), name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.NewVirtualMachine(c, info.Result.(types.ManagedObjectReference)), nil
+}
@@ -0,0 +1,319 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Properties of a VM list entry. Only the properties a listing needs are
+// retrieved, so callers pass the subset matching the columns they print.
+const (
+	VMPropParent       = "parent"
+	VMPropPowerState   = "summary.runtime.powerState"
+	VMPropHost         = "summary.runtime.host"
+	VMPropCPUs         = "summary.config.numCpu"
+	VMPropMemory       = "summary.config.memorySizeMB"
+	VMPropGuestID      = "summary.config.guestId"
+	VMPropGuestOS      = "summary.config.guestFullName"
+	VMPropUUID         = "summary.config.uuid"
+	VMPropInstanceUUID = "summary.config.instanceUuid"
+	VMPropIPAddress    = "summary.guest.ipAddress"
+	VMPropTools        = "summary.guest.toolsRunningStatus"
+	VMPropCommitted    = "summary.storage.committed"
+)
+
+// VMListEntry is a VM as shown by vm list. Fields whose property was not
+// retrieved are left empty.
+type VMListEntry struct {
+	Name           string `json:"name" yaml:"name"`
+	Moid           string `json:"moid" yaml:"moid"`
+	PowerState     string `json:"powerState,omitempty" yaml:"powerState,omitempty"`
+	CPUs           int32  `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	MemoryMB       int32  `json:"memoryMB,omitempty" yaml:"memoryMB,omitempty"`
+	GuestID        string `json:"guestId,omitempty" yaml:"guestId,omitempty"`
+	GuestOS        string `json:"guestOS,omitempty" yaml:"guestOS,omitempty"`
+	IPAddress      string `json:"ipAddress,omitempty" yaml:"ipAddress,omitempty"`
+	Tools          string `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Host           string `json:"host,omitempty" yaml:"host,omitempty"`
+	Cluster        string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	Folder         string `json:"folder,omitempty" yaml:"folder,omitempty"`
+	UUID           string `json:"uuid,omitempty" yaml:"uuid,omitempty"`
+	InstanceUUID   string `json:"instanceUuid,omitempty" yaml:"instanceUuid,omitempty"`
+	CommittedBytes int64  `json:"committedBytes,omitempty" yaml:"committedBytes,omitempty"`
+}
+
+// VMFilter selects the VMs returned by FilterVMs. Zero-valued fields match
+// every VM.
+type VMFilter struct {
+	// Root is the folder or datacenter to search under
+	Root types.ManagedObjectReference
+	// Hosts restricts the result to VMs running on these hosts
+	Hosts map[types.ManagedObjectReference]bool
+	// VMs restricts the result to these VMs, e.g. the ones carrying a tag
+	VMs        map[types.ManagedObjectReference]bool
+	Name       func(string) bool
+	PowerState types.VirtualMachinePowerState
+	// GuestOS is a case-insensitive glob matched against the guest ID and
+	// the guest full name
+	GuestOS string
+}
+
+// FilterVMs lists the VMs under filter.Root that match filter, retrieving
+// props for each in a single PropertyCollector call. Templates are skipped.
+// Host and cluster names are resolved when props include VMPropHost, and
+// folder names when they include VMPropParent.
+func FilterVMs(ctx context.Context, c *vim25.Client, filter VMFilter, props []string) ([]VMListEntry, error) {
+	wantHost := containsProp(props, VMPropHost)
+	wantFolder := containsProp(props, VMPropParent)
+
+	query := append([]string{"name", "summary.config.template"}, props...)
+	if filter.PowerState != "" {
+		query = append(query, VMPropPowerState)
+	}
+	if filter.Hosts != nil {
+		query = append(query, VMPropHost)
+	}
+	if filter.GuestOS != "" {
+		query = append(query, VMPropGuestID, VMPropGuestOS)
+	}
+
+	vms, err := ListVMs(ctx, c, filter.Root, dedupeProps(query))
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []mo.VirtualMachine
+	for _, vm := range vms {
+		ok, err := filter.match(vm)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, vm)
+		}
+	}
+
+	var hosts map[types.ManagedObjectReference]hostPlacement
+	if wantHost {
+		if hosts, err = hostPlacements(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	var folders map[types.ManagedObjectReference]string
+	if wantFolder {
+		var parents []types.ManagedObjectReference
+		for _, vm := range matched {
+			if vm.Parent != nil {
+				parents = append(parents, *vm.Parent)
+			}
+		}
+		if folders, err = EntityNames(ctx, c, dedupeRefs(parents)); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := make([]VMListEntry, 0, len(matched))
+	for _, vm := range matched {
+		s := vm.Summary
+		e := VMListEntry{
+			Name:         vm.Name,
+			Moid:         vm.Reference().Value,
+			PowerState:   string(s.Runtime.PowerState),
+			CPUs:         s.Config.NumCpu,
+			MemoryMB:     s.Config.MemorySizeMB,
+			GuestID:      s.Config.GuestId,
+			GuestOS:      s.Config.GuestFullName,
+			UUID:         s.Config.Uuid,
+			InstanceUUID: s.Config.InstanceUuid,
+		}
+		if s.Guest != nil {
+			e.IPAddress = s.Guest.IpAddress
+			e.Tools = s.Guest.ToolsRunningStatus
+		}
+		if s.Storage != nil {
+			e.CommittedBytes = s.Storage.Committed
+		}
+		if wantHost && s.Runtime.Host != nil {
+			h := hosts[*s.Runtime.Host]
+			e.Host, e.Cluster = h.host, h.cluster
+		}
+		if wantFolder && vm.Parent != nil {
+			e.Folder = folders[*vm.Parent]
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func (f VMFilter) match(vm mo.VirtualMachine) (bool, error) {
+	s := vm.Summary
+	switch {
+	case s.Config.Template:
+		return false, nil
+	case f.VMs != nil && !f.VMs[vm.Reference()]:
+		return false, nil
+	case f.Name != nil && !f.Name(vm.Name):
+		return false, nil
+	case f.PowerState != "" && s.Runtime.PowerState != f.PowerState:
+		return false, nil
+	case f.Hosts != nil && (s.Runtime.Host == nil || !f.Hosts[*s.Runtime.Host]):
+		return false, nil
+	}
+
+	if f.GuestOS != "" {
+		pattern := strings.ToLower(f.GuestOS)
+		for _, candidate := range []string{s.Config.GuestId, s.Config.GuestFullName} {
+			ok, err := path.Match(pattern, strings.ToLower(candidate))
+			if err != nil {
+				return false, fmt.Errorf("invalid guest OS pattern %q: %w", f.GuestOS, err)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// NameMatcher compiles a VM name pattern. Patterns wrapped in slashes, such
+// as /^web-\d+$/, are regular expressions; anything else is a shell glob.
+func NameMatcher(pattern string) (func(string) bool, error) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid name regex: %w", err)
+		}
+		return re.MatchString, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid name glob %q: %w", pattern, err)
+	}
+	return func(name string) bool {
+		ok, _ := path.Match(pattern, name)
+		return ok
+	}, nil
+}
+
+// TaggedVMs returns the VMs carrying the tag, given as "name" or
+// "category:name". Tags live in the vAPI endpoint, which needs its own
+// session.
+func TaggedVMs(ctx context.Context, c *vim25.Client, username, password, tag string) (map[types.ManagedObjectReference]bool, error) {
+	rc := rest.NewClient(c)
+	if err := rc.Login(ctx, url.UserPassword(username, password)); err != nil {
+		return nil, fmt.Errorf("failed to log in to the vAPI endpoint: %w", err)
+	}
+	defer rc.Logout(ctx)
+
+	m := tags.NewManager(rc)
+	category, name, ok := strings.Cut(tag, ":")
+	if !ok {
+		category, name = "", tag
+	}
+	t, err := m.GetTagForCategory(ctx, name, category)
+	if err != nil {
+		return nil, fmt.Errorf("tag %q not found: %w", tag, err)
+	}
+
+	objs, err := m.ListAttachedObjects(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	vms := make(map[types.ManagedObjectReference]bool)
+	for _, obj := range objs {
+		if ref := obj.Reference(); ref.Type == "VirtualMachine" {
+			vms[ref] = true
+		}
+	}
+	return vms, nil
+}
+
+// hostPlacement is the name of a host and of the cluster it belongs to
+type hostPlacement struct {
+	host    string
+	cluster string
+}
+
+// hostPlacements resolves the name and cluster of every host in one pass,
+// rather than per VM
+func hostPlacements(ctx context.Context, c *vim25.Client) (map[types.ManagedObjectReference]hostPlacement, error) {
+	v, err := view.NewManager(c).CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"HostSystem"}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer v.Destroy(ctx)
+
+	var hosts []mo.HostSystem
+	if err := v.Retrieve(ctx, []string{"HostSystem"}, []string{"name", "parent"}, &hosts); err != nil {
+		return nil, err
+	}
+
+	var clusters []types.ManagedObjectReference
+	for _, h := range hosts {
+		if h.Parent != nil && h.Parent.Type == "ClusterComputeResource" {
+			clusters = append(clusters, *h.Parent)
+		}
+	}
+	names, err := EntityNames(ctx, c, dedupeRefs(clusters))
+	if err != nil {
+		return nil, err
+	}
+
+	placements := make(map[types.ManagedObjectReference]hostPlacement, len(hosts))
+	for _, h := range hosts {
+		p := hostPlacement{host: h.Name}
+		if h.Parent != nil {
+			p.cluster = names[*h.Parent]
+		}
+		placements[h.Reference()] = p
+	}
+	return placements, nil
+}
+
+func containsProp(props []string, prop string) bool {
+	for _, p := range props {
+		if p == prop {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeProps(props []string) []string {
+	seen := make(map[string]bool, len(props))
+	out := make([]string, 0, len(props))
+	for _, p := range props {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func dedupeRefs(refs []types.ManagedObjectReference) []types.ManagedObjectReference {
+	seen := make(map[types.ManagedObjectReference]bool, len(refs))
+	out := make([]types.ManagedObjectReference, 0, len(refs))
+	for _, r := range refs {
+		if !seen[r] {
+			seen[r] = true
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,347 @@
+package vsphere
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// VMReport is the full description of a VM shown by inspect vm
+type VMReport struct {
+	Name       string      `json:"name" yaml:"name"`
+	Moid       string      `json:"moid" yaml:"moid"`
+	PowerState string      `json:"powerState" yaml:"powerState"`
+	General    VMGeneral   `json:"general" yaml:"general"`
+	Hardware   VMHardware  `json:"hardware" yaml:"hardware"`
+	Compute    VMCompute   `json:"compute" yaml:"compute"`
+	Storage    VMStorage   `json:"storage" yaml:"storage"`
+	Network    []VMNIC     `json:"network" yaml:"network"`
+	Snapshots  VMSnapshots `json:"snapshots" yaml:"snapshots"`
+	Metadata   VMMetadata  `json:"metadata" yaml:"metadata"`
+}
+
+// VMGeneral holds identity, guest OS and VMware Tools details
+type VMGeneral struct {
+	UUID         string `json:"uuid" yaml:"uuid"`
+	InstanceUUID string `json:"instanceUuid" yaml:"instanceUuid"`
+	GuestOS      string `json:"guestOS" yaml:"guestOS"`
+	GuestID      string `json:"guestId" yaml:"guestId"`
+	Hostname     string `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	ToolsStatus  string `json:"toolsStatus" yaml:"toolsStatus"`
+	ToolsVersion string `json:"toolsVersion,omitempty" yaml:"toolsVersion,omitempty"`
+	Template     bool   `json:"template" yaml:"template"`
+}
+
+// VMHardware holds the virtual hardware configuration
+type VMHardware struct {
+	CPUs            int32  `json:"cpus" yaml:"cpus"`
+	CoresPerSocket  int32  `json:"coresPerSocket" yaml:"coresPerSocket"`
+	MemoryMB        int32  `json:"memoryMB" yaml:"memoryMB"`
+	NICs            int    `json:"nics" yaml:"nics"`
+	Disks           int    `json:"disks" yaml:"disks"`
+	HardwareVersion string `json:"hardwareVersion" yaml:"hardwareVersion"`
+	Firmware        string `json:"firmware" yaml:"firmware"`
+}
+
+// VMCompute holds the VM's placement
+type VMCompute struct {
+	Host         string `json:"host" yaml:"host"`
+	Cluster      string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	ResourcePool string `json:"resourcePool,omitempty" yaml:"resourcePool,omitempty"`
+}
+
+// VMStorage holds datastore usage and per-disk details
+type VMStorage struct {
+	Datastores       []string `json:"datastores" yaml:"datastores"`
+	ProvisionedBytes int64    `json:"provisionedBytes" yaml:"provisionedBytes"`
+	UsedBytes        int64    `json:"usedBytes" yaml:"usedBytes"`
+	Disks            []VMDisk `json:"disks" yaml:"disks"`
+}
+
+// VMDisk describes a single virtual disk
+type VMDisk struct {
+	Label         string `json:"label" yaml:"label"`
+	File          string `json:"file" yaml:"file"`
+	CapacityBytes int64  `json:"capacityBytes" yaml:"capacityBytes"`
+	UsedBytes     int64  `json:"usedBytes" yaml:"usedBytes"`
+	Thin          bool   `json:"thin" yaml:"thin"`
+}
+
+// VMNIC describes a single network adapter
+type VMNIC struct {
+	Label       string   `json:"label" yaml:"label"`
+	Network     string   `json:"network" yaml:"network"`
+	AdapterType string   `json:"adapterType" yaml:"adapterType"`
+	MAC         string   `json:"mac" yaml:"mac"`
+	Connected   bool     `json:"connected" yaml:"connected"`
+	IP          string   `json:"ip,omitempty" yaml:"ip,omitempty"`
+	IPs         []string `json:"ips,omitempty" yaml:"ips,omitempty"`
+}
+
+// VMSnapshots summarizes the VM's snapshot tree
+type VMSnapshots struct {
+	Count      int    `json:"count" yaml:"count"`
+	Current    string `json:"current,omitempty" yaml:"current,omitempty"`
+	TotalBytes int64  `json:"totalBytes" yaml:"totalBytes"`
+}
+
+// VMMetadata holds timestamps and notes
+type VMMetadata struct {
+	CreatedAt  *time.Time `json:"createdAt,omitempty" yaml:"createdAt,omitempty"`
+	ModifiedAt time.Time  `json:"modifiedAt" yaml:"modifiedAt"`
+	Annotation string     `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+}
+
+// vmReportProps are the VM properties BuildVMReport reads
+var vmReportProps = []string{"name", "config", "runtime", "guest", "storage", "layoutEx", "snapshot", "resourcePool", "network"}
+
+// BuildVMReport collects the full report for a VM
+func BuildVMReport(ctx context.Context, c *vim25.Client, vm *object.VirtualMachine) (*VMReport, error) {
+	var props mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), vmReportProps, &props); err != nil {
+		return nil, err
+	}
+
+	r := &VMReport{
+		Name:       props.Name,
+		Moid:       props.Reference().Value,
+		PowerState: string(props.Runtime.PowerState),
+		Network:    []VMNIC{},
+		Storage:    VMStorage{Datastores: []string{}, Disks: []VMDisk{}},
+	}
+
+	names, err := entityNames(ctx, c, props)
+	if err != nil {
+		return nil, err
+	}
+
+	if props.Guest != nil {
+		r.General.Hostname = props.Guest.HostName
+		r.General.ToolsStatus = props.Guest.ToolsRunningStatus
+		r.General.ToolsVersion = props.Guest.ToolsVersion
+	}
+
+	if props.Runtime.Host != nil {
+		r.Compute.Host = names[*props.Runtime.Host]
+		r.Compute.Cluster, err = HostCluster(ctx, c, *props.Runtime.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if props.ResourcePool != nil {
+		r.Compute.ResourcePool = names[*props.ResourcePool]
+	}
+
+	if props.Storage != nil {
+		for _, u := range props.Storage.PerDatastoreUsage {
+			r.Storage.Datastores = append(r.Storage.Datastores, names[u.Datastore])
+			r.Storage.ProvisionedBytes += u.Committed + u.Uncommitted
+			r.Storage.UsedBytes += u.Committed
+		}
+	}
+
+	fileSizes := make(map[int32]int64)
+	if props.LayoutEx != nil {
+		for _, f := range props.LayoutEx.File {
+			fileSizes[f.Key] = f.Size
+			if f.Type == string(types.VirtualMachineFileLayoutExFileTypeSnapshotData) ||
+				f.Type == string(types.VirtualMachineFileLayoutExFileTypeSnapshotMemory) {
+				r.Snapshots.TotalBytes += f.Size
+			}
+		}
+	}
+
+	if props.Config != nil {
+		fillConfig(r, props, names, fileSizes)
+	}
+
+	if props.Snapshot != nil {
+		r.Snapshots.Count = countSnapshots(props.Snapshot.RootSnapshotList)
+		if props.Snapshot.CurrentSnapshot != nil {
+			r.Snapshots.Current = snapshotName(props.Snapshot.RootSnapshotList, *props.Snapshot.CurrentSnapshot)
+		}
+	}
+
+	return r, nil
+}
+
+// fillConfig populates the report sections that come from the VM's config
+func fillConfig(r *VMReport, props mo.VirtualMachine, names map[types.ManagedObjectReference]string, fileSizes map[int32]int64) {
+	cfg := props.Config
+
+	r.General.UUID = cfg.Uuid
+	r.General.InstanceUUID = cfg.InstanceUuid
+	r.General.GuestOS = cfg.GuestFullName
+	r.General.GuestID = cfg.GuestId
+	r.General.Template = cfg.Template
+
+	r.Hardware.CPUs = cfg.Hardware.NumCPU
+	r.Hardware.CoresPerSocket = cfg.Hardware.NumCoresPerSocket
+	r.Hardware.MemoryMB = cfg.Hardware.MemoryMB
+	r.Hardware.HardwareVersion = cfg.Version
+	r.Hardware.Firmware = cfg.Firmware
+
+	r.Metadata.CreatedAt = cfg.CreateDate
+	r.Metadata.ModifiedAt = cfg.Modified
+	r.Metadata.Annotation = cfg.Annotation
+
+	// Disk usage is the sum of every file in the disk's chain, delta disks included
+	diskUsed := make(map[int32]int64)
+	if props.LayoutEx != nil {
+		for _, d := range props.LayoutEx.Disk {
+			for i, link := range d.Chain {
+				for _, key := range link.FileKey {
+					diskUsed[d.Key] += fileSizes[key]
+					if i > 0 {
+						r.Snapshots.TotalBytes += fileSizes[key]
+					}
+				}
+			}
+		}
+	}
+
+	guestNICs := make(map[int32]types.GuestNicInfo)
+	if props.Guest != nil {
+		for _, n := range props.Guest.Net {
+			guestNICs[n.DeviceConfigId] = n
+		}
+	}
+
+	devices := object.VirtualDeviceList(cfg.Hardware.Device)
+	for _, d := range devices {
+		switch dev := d.(type) {
+		case *types.VirtualDisk:
+			disk := VMDisk{
+				Label:         devices.Name(dev),
+				CapacityBytes: dev.CapacityInBytes,
+				UsedBytes:     diskUsed[dev.Key],
+			}
+			if info := dev.DeviceInfo; info != nil {
+				disk.Label = info.GetDescription().Label
+			}
+			if b, ok := dev.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+				disk.File = b.GetVirtualDeviceFileBackingInfo().FileName
+			}
+			if b, ok := dev.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok && b.ThinProvisioned != nil {
+				disk.Thin = *b.ThinProvisioned
+			}
+			r.Storage.Disks = append(r.Storage.Disks, disk)
+		case types.BaseVirtualEthernetCard:
+			card := dev.GetVirtualEthernetCard()
+			nic := VMNIC{
+				Label:       devices.Name(d),
+				Network:     nicNetwork(card, names),
+				AdapterType: devices.Type(d),
+				MAC:         card.MacAddress,
+			}
+			if info := card.DeviceInfo; info != nil {
+				nic.Label = info.GetDescription().Label
+			}
+			if card.Connectable != nil {
+				nic.Connected = card.Connectable.Connected
+			}
+			if g, ok := guestNICs[card.Key]; ok {
+				nic.IPs = g.IpAddress
+				for _, ip := range g.IpAddress {
+					if isIPv4(ip) {
+						nic.IP = ip
+						break
+					}
+				}
+			}
+			r.Network = append(r.Network, nic)
+		}
+	}
+
+	r.Hardware.Disks = len(r.Storage.Disks)
+	r.Hardware.NICs = len(r.Network)
+}
+
+// nicNetwork returns the name of the network a NIC is attached to
+func nicNetwork(card *types.VirtualEthernetCard, names map[types.ManagedObjectReference]string) string {
+	switch b := card.Backing.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		return b.DeviceName
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		ref := types.ManagedObjectReference{Type: "DistributedVirtualPortgroup", Value: b.Port.PortgroupKey}
+		if name, ok := names[ref]; ok {
+			return name
+		}
+		return b.Port.PortgroupKey
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkId
+	default:
+		return ""
+	}
+}
+
+// entityNames resolves the names of every managed object the VM refers to
+func entityNames(ctx context.Context, c *vim25.Client, vm mo.VirtualMachine) (map[types.ManagedObjectReference]string, error) {
+	var refs []types.ManagedObjectReference
+	if vm.Runtime.Host != nil {
+		refs = append(refs, *vm.Runtime.Host)
+	}
+	if vm.ResourcePool != nil {
+		refs = append(refs, *vm.ResourcePool)
+	}
+	refs = append(refs, vm.Network...)
+	if vm.Storage != nil {
+		for _, u := range vm.Storage.PerDatastoreUsage {
+			refs = append(refs, u.Datastore)
+		}
+	}
+
+	return EntityNames(ctx, c, refs)
+}
+
+// HostCluster returns the name of the cluster a host belongs to, or an
+// empty string for standalone hosts
+func HostCluster(ctx context.Context, c *vim25.Client, host types.ManagedObjectReference) (string, error) {
+	pc := property.DefaultCollector(c)
+
+	var h mo.HostSystem
+	if err := pc.RetrieveOne(ctx, host, []string{"parent"}, &h); err != nil {
+		return "", err
+	}
+	if h.Parent == nil || h.Parent.Type != "ClusterComputeResource" {
+		return "", nil
+	}
+
+	var cluster mo.ManagedEntity
+	if err := pc.RetrieveOne(ctx, *h.Parent, []string{"name"}, &cluster); err != nil {
+		return "", err
+	}
+
+	return cluster.Name, nil
+}
+
+func countSnapshots(tree []types.VirtualMachineSnapshotTree) int {
+	n := 0
+	for _, s := range tree {
+		n += 1 + countSnapshots(s.ChildSnapshotList)
+	}
+	return n
+}
+
+func isIPv4(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() != nil
+}
+
+func snapshotName(tree []types.VirtualMachineSnapshotTree, ref types.ManagedObjectReference) string {
+	for _, s := range tree {
+		if s.Snapshot == ref {
+			return s.Name
+		}
+		if name := snapshotName(s.ChildSnapshotList, ref); name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work identified by its position in the batch
+type Job func(ctx context.Context, index int) error
+
+// Result holds the outcome of a single job
+type Result struct {
+	Index    int
+	Duration time.Duration
+	Err      error
+}
+
+// Run executes count jobs with at most parallel of them in flight at once.
+// Results are returned in job order regardless of completion order.
+func Run(ctx context.Context, count, parallel int, job Job) []Result {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]Result, count)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallel && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				start := time.Now()
+				err := job(ctx, i)
+				results[i] = Result{Index: i, Duration: time.Since(start), Err: err}
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		if ctx.Err() != nil {
+			results[i] = Result{Index: i, Err: ctx.Err()}
+			continue
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// RetryPolicy controls how Retry re-runs a failing function
+type RetryPolicy struct {
+	// Retries is the number of additional attempts after the first one
+	Retries int
+	// Delay is the wait before the first retry; it doubles on every retry
+	Delay time.Duration
+	// Retryable reports whether an error is worth retrying. Nil retries everything.
+	Retryable func(error) bool
+}
+
+// Retry calls fn until it succeeds, fails with a non-retryable error, or the
+// retry budget is spent. It returns the number of attempts made.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) (int, error) {
+	delay := policy.Delay
+	attempts := 0
+
+	for {
+		attempts++
+		err := fn(ctx)
+		if err == nil {
+			return attempts, nil
+		}
+		if attempts > policy.Retries || (policy.Retryable != nil && !policy.Retryable(err)) {
+			return attempts, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
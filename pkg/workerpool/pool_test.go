@@ -0,0 +1,162 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBoundsParallelism(t *testing.T) {
+	const count, parallel = 20, 3
+
+	var inFlight, peak int32
+	results := Run(context.Background(), count, parallel, func(ctx context.Context, i int) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		return nil
+	})
+
+	if len(results) != count {
+		t.Fatalf("Run returned %d results, want %d", len(results), count)
+	}
+	if peak > parallel {
+		t.Errorf("Run had %d jobs in flight, want at most %d", peak, parallel)
+	}
+	if peak < 2 {
+		t.Errorf("Run had %d jobs in flight, want jobs to run concurrently", peak)
+	}
+}
+
+func TestRunReportsErrorsByIndex(t *testing.T) {
+	errOdd := errors.New("odd")
+
+	results := Run(context.Background(), 6, 2, func(ctx context.Context, i int) error {
+		// Finish out of order so results cannot line up by accident
+		time.Sleep(time.Duration(6-i) * time.Millisecond)
+		if i%2 == 1 {
+			return errOdd
+		}
+		return nil
+	})
+
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("results[%d].Index = %d, want %d", i, r.Index, i)
+		}
+		want := error(nil)
+		if i%2 == 1 {
+			want = errOdd
+		}
+		if r.Err != want {
+			t.Errorf("results[%d].Err = %v, want %v", i, r.Err, want)
+		}
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	ran := map[int]bool{}
+	results := Run(ctx, 5, 1, func(ctx context.Context, i int) error {
+		mu.Lock()
+		ran[i] = true
+		mu.Unlock()
+		if i == 1 {
+			cancel()
+		}
+		return nil
+	})
+
+	for i, r := range results {
+		if !ran[i] && !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d].Err = %v for a job that never ran, want %v", i, r.Err, context.Canceled)
+		}
+	}
+	if ran[4] {
+		t.Errorf("Run started job 4 after the context was cancelled")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+	retryable := func(err error) bool { return err == errTransient }
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{"first attempt succeeds", RetryPolicy{Retries: 2}, nil, 1, nil},
+		{"succeeds after retries", RetryPolicy{Retries: 2, Retryable: retryable}, []error{errTransient, errTransient}, 3, nil},
+		{"retries exhausted", RetryPolicy{Retries: 2, Retryable: retryable}, []error{errTransient, errTransient, errTransient}, 3, errTransient},
+		{"non-retryable error", RetryPolicy{Retries: 2, Retryable: retryable}, []error{errFatal}, 1, errFatal},
+		{"non-retryable after a retry", RetryPolicy{Retries: 2, Retryable: retryable}, []error{errTransient, errFatal}, 2, errFatal},
+		{"nil Retryable retries everything", RetryPolicy{Retries: 1}, []error{errFatal}, 2, nil},
+		{"no retries", RetryPolicy{}, []error{errTransient}, 1, errTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			attempts, err := Retry(context.Background(), tt.policy, func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("Retry made %d calls and reported %d attempts, want %d", calls, attempts, tt.wantAttempts)
+			}
+			if err != tt.wantErr {
+				t.Errorf("Retry error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryCancelledDuringDelay(t *testing.T) {
+	errTransient := errors.New("transient")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	policy := RetryPolicy{Retries: 3, Delay: time.Hour}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	done := make(chan struct{})
+	var (
+		attempts int
+		err      error
+	)
+	go func() {
+		defer close(done)
+		attempts, err = Retry(ctx, policy, func(ctx context.Context) error {
+			return errTransient
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Retry did not return after the context was cancelled")
+	}
+	if attempts != 1 {
+		t.Errorf("Retry reported %d attempts, want 1", attempts)
+	}
+	if err != errTransient {
+		t.Errorf("Retry error = %v, want the last attempt's error %v", err, errTransient)
+	}
+}